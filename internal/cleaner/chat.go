@@ -2,25 +2,95 @@ package cleaner
 
 import (
 	"context"
+	"expvar"
 	"time"
 
-	chatRepo "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/repository/chat"
+	domain "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/chat"
 	"go.uber.org/zap"
 )
 
-func StartChatCleaner(repo *chatRepo.Repository, logger *zap.Logger) {
-	go func() {
-		ticker := time.NewTicker(1 * time.Hour)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ticker.C:
-				err := repo.DeleteOldMessages(context.Background(), 24*time.Hour)
-				if err != nil {
-					logger.Fatal("Failed to clear messages:", zap.Error(err))
-				}
-			}
+// stats is published at /debug/vars as chat_retention.
+var stats = expvar.NewMap("chat_retention")
+
+type Repository interface {
+	ListRooms(ctx context.Context) ([]domain.Room, error)
+	PurgeMessages(ctx context.Context, roomID int, p domain.RetentionPolicy) (int64, error)
+}
+
+// RunStats describes one pass of the cleaner.
+type RunStats struct {
+	Rooms    int
+	Deleted  int64
+	Archived int64
+	Failed   int
+}
+
+// ChatCleaner periodically applies the retention policy to every chat room.
+type ChatCleaner struct {
+	repo     Repository
+	policy   domain.RetentionPolicy
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewChatCleaner(repo Repository, policy domain.RetentionPolicy, interval time.Duration, logger *zap.Logger) *ChatCleaner {
+	return &ChatCleaner{repo: repo, policy: policy, interval: interval, logger: logger}
+}
+
+// Run cleans the rooms every interval until ctx is cancelled. Errors are
+// logged and retried on the next pass.
+func (c *ChatCleaner) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.Clean(ctx)
+		case <-ctx.Done():
+			c.logger.Info("Chat cleaner stopped")
+			return
+		}
+	}
+}
+
+// Clean applies the retention policy to every room once.
+func (c *ChatCleaner) Clean(ctx context.Context) RunStats {
+	var run RunStats
+	rooms, err := c.repo.ListRooms(ctx)
+	if err != nil {
+		c.logger.Error("Failed to list chat rooms for cleaning", zap.Error(err))
+		stats.Add("errors", 1)
+		return run
+	}
+
+	for _, room := range rooms {
+		if ctx.Err() != nil {
+			break
+		}
+		p := c.policy.With(room.Retention)
+		n, err := c.repo.PurgeMessages(ctx, room.ID, p)
+		if err != nil {
+			c.logger.Error("Failed to clean chat room", zap.Int("roomID", room.ID), zap.Error(err))
+			run.Failed++
+			continue
 		}
-	}()
+		run.Rooms++
+		if p.Archive {
+			run.Archived += n
+		} else {
+			run.Deleted += n
+		}
+	}
+
+	stats.Add("runs", 1)
+	stats.Add("deleted", run.Deleted)
+	stats.Add("archived", run.Archived)
+	stats.Add("errors", int64(run.Failed))
+	c.logger.Info("Chat cleaner finished",
+		zap.Int("rooms", run.Rooms),
+		zap.Int64("deleted", run.Deleted),
+		zap.Int64("archived", run.Archived),
+		zap.Int("failed", run.Failed))
+	return run
 }
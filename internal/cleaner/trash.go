@@ -0,0 +1,66 @@
+package cleaner
+
+import (
+	"context"
+	"expvar"
+	"time"
+
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/trash"
+	"go.uber.org/zap"
+)
+
+// trashStats is published at /debug/vars as trash_purge.
+var trashStats = expvar.NewMap("trash_purge")
+
+type TrashRepository interface {
+	Purge(ctx context.Context, before time.Time) (trash.Purged, error)
+}
+
+// TrashCleaner periodically removes topics, posts and comments that have
+// been in the trash for longer than the grace period.
+type TrashCleaner struct {
+	repo     TrashRepository
+	grace    time.Duration
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewTrashCleaner(repo TrashRepository, grace, interval time.Duration, logger *zap.Logger) *TrashCleaner {
+	return &TrashCleaner{repo: repo, grace: grace, interval: interval, logger: logger}
+}
+
+// Run purges the trash every interval until ctx is cancelled. Errors are
+// logged and retried on the next pass.
+func (c *TrashCleaner) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.Clean(ctx)
+		case <-ctx.Done():
+			c.logger.Info("Trash cleaner stopped")
+			return
+		}
+	}
+}
+
+// Clean purges the trash once.
+func (c *TrashCleaner) Clean(ctx context.Context) trash.Purged {
+	purged, err := c.repo.Purge(ctx, time.Now().Add(-c.grace))
+	trashStats.Add("runs", 1)
+	trashStats.Add("topics", purged.Topics)
+	trashStats.Add("posts", purged.Posts)
+	trashStats.Add("comments", purged.Comments)
+	if err != nil {
+		c.logger.Error("Failed to purge trash", zap.Error(err))
+		trashStats.Add("errors", 1)
+		return purged
+	}
+	c.logger.Info("Trash cleaner finished",
+		zap.Int64("topics", purged.Topics),
+		zap.Int64("posts", purged.Posts),
+		zap.Int64("comments", purged.Comments))
+	return purged
+}
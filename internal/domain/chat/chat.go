@@ -1,10 +1,204 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// DefaultRoom is used when a client does not ask for a specific room.
+const DefaultRoom = "general"
+
+var (
+	ErrRoomNotFound    = errors.New("chat room not found")
+	ErrRoomExists      = errors.New("chat room already exists")
+	ErrInvalidRoomName = errors.New("invalid chat room name")
+	ErrMessageTooLong  = errors.New("chat message too long")
+	ErrInvalidPeer     = errors.New("invalid direct message recipient")
+	ErrMessageNotFound = errors.New("chat message not found")
+	ErrForbidden       = errors.New("not allowed to modify chat message")
+	ErrEditWindow      = errors.New("chat message can no longer be changed")
+	ErrNotEditable     = errors.New("only text chat messages can be edited")
+	ErrBanned          = errors.New("banned from chat room")
+	ErrMuted           = errors.New("muted in chat")
+	ErrSlowMode        = errors.New("slow mode is on")
+	ErrUnknownCommand  = errors.New("unknown command")
+	ErrCommandUsage    = errors.New("usage")
+)
+
+// Kinds of chat messages. Messages other than text and bot are produced by
+// slash commands.
+const (
+	KindText   = "text"
+	KindBot    = "bot"    // plain text reply posted by an in-process bot
+	KindAction = "action" // "/me waves", rendered as "* user waves"
+	KindTopic  = "topic"  // link to a forum topic, Attachment is a TopicPreview
+	KindPost   = "post"   // preview of a forum post, Attachment is a PostPreview
+)
 
 type ChatMessage struct {
+	ID         int             `json:"id"`
+	RoomID     int             `json:"room_id"`
+	Username   string          `json:"username"`
+	Kind       string          `json:"kind"`
+	Content    string          `json:"content"`
+	Attachment json.RawMessage `json:"attachment,omitempty" swaggertype:"object"`
+	Timestamp  time.Time       `json:"timestamp"`
+	EditedAt   *time.Time      `json:"edited_at,omitempty"`
+	Reactions  map[string]int  `json:"reactions,omitempty"` // emoji to number of users
+}
+
+type TopicPreview struct {
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+type PostPreview struct {
 	ID        int       `json:"id"`
+	TopicID   int       `json:"topic_id"`
+	Title     string    `json:"title"`
+	Excerpt   string    `json:"excerpt"`
 	Username  string    `json:"username"`
-	Content   string    `json:"content"`
 	Timestamp time.Time `json:"timestamp"`
 }
+
+// MessageDeletion tells clients that a message was removed from a room.
+type MessageDeletion struct {
+	ID        int    `json:"id"`
+	RoomID    int    `json:"room_id"`
+	DeletedBy string `json:"deleted_by"`
+}
+
+// HistoryQuery selects a window of a room's messages by id.
+type HistoryQuery struct {
+	SinceID  int // only messages with a greater id, oldest first
+	BeforeID int // only messages with a smaller id
+	Limit    int
+}
+
+// Room is a chat channel. Topic rooms are named "topic-<id>" and have TopicID set.
+type Room struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Title     string    `json:"title"`
+	TopicID   *int      `json:"topic_id,omitempty"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	SlowMode  int       `json:"slow_mode_seconds"` // minimum seconds between a user's messages, 0 when off
+	Retention Retention `json:"retention"`
+}
+
+// MaxRetentionAge is the longest MaxAgeSeconds a room may keep messages for.
+const MaxRetentionAge = 10 * 365 * 24 * time.Hour
+
+// Retention overrides the global retention policy for a room. Nil fields use
+// the global setting; a zero MaxAgeSeconds or MaxMessages disables that limit.
+type Retention struct {
+	MaxAgeSeconds *int  `json:"max_age_seconds,omitempty"`
+	MaxMessages   *int  `json:"max_messages,omitempty"`
+	Archive       *bool `json:"archive,omitempty"`
+}
+
+// RetentionPolicy decides which messages of a room the cleaner removes:
+// those older than MaxAge and those beyond the newest MaxMessages. Zero
+// values disable a limit. With Archive set, messages are moved to the
+// archive table instead of being deleted.
+type RetentionPolicy struct {
+	MaxAge      time.Duration
+	MaxMessages int
+	Archive     bool
+}
+
+// With applies a room's overrides to the policy.
+func (p RetentionPolicy) With(r Retention) RetentionPolicy {
+	if r.MaxAgeSeconds != nil {
+		p.MaxAge = time.Duration(*r.MaxAgeSeconds) * time.Second
+	}
+	if r.MaxMessages != nil {
+		p.MaxMessages = *r.MaxMessages
+	}
+	if r.Archive != nil {
+		p.Archive = *r.Archive
+	}
+	return p
+}
+
+type Member struct {
+	RoomID     int       `json:"room_id"`
+	UserID     int32     `json:"user_id"`
+	Username   string    `json:"username"`
+	JoinedAt   time.Time `json:"joined_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+// DirectMessage is a private message between two users.
+type DirectMessage struct {
+	ID                int        `json:"id"`
+	SenderID          int32      `json:"sender_id"`
+	SenderUsername    string     `json:"sender_username"`
+	RecipientID       int32      `json:"recipient_id"`
+	RecipientUsername string     `json:"recipient_username"`
+	Content           string     `json:"content"`
+	CreatedAt         time.Time  `json:"created_at"`
+	ReadAt            *time.Time `json:"read_at,omitempty"`
+}
+
+// Conversation is one inbox entry: the other participant, the latest message
+// and how many messages from them are still unread.
+type Conversation struct {
+	UserID      int32         `json:"user_id"`
+	Username    string        `json:"username"`
+	LastMessage DirectMessage `json:"last_message"`
+	Unread      int           `json:"unread"`
+}
+
+// Presence is a user connected to a room on any server instance.
+type Presence struct {
+	RoomID   int    `json:"room_id"`
+	UserID   int32  `json:"user_id"`
+	Username string `json:"username"`
+}
+
+// Typing is a short-lived notice that a user is composing a message in a room.
+type Typing struct {
+	RoomID   int    `json:"room_id"`
+	Username string `json:"username"`
+}
+
+// Mute stops a user from sending messages until Until. A mute without
+// RoomID applies to every room.
+type Mute struct {
+	ID        int       `json:"id"`
+	RoomID    *int      `json:"room_id,omitempty"`
+	UserID    int32     `json:"user_id"`
+	Username  string    `json:"username"`
+	Until     time.Time `json:"until"`
+	Reason    string    `json:"reason"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Ban keeps a user out of a room until it is lifted.
+type Ban struct {
+	RoomID    int       `json:"room_id"`
+	UserID    int32     `json:"user_id"`
+	Username  string    `json:"username"`
+	Reason    string    `json:"reason"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Moderation lists the active mutes and bans.
+type Moderation struct {
+	Mutes []Mute `json:"mutes"`
+	Bans  []Ban  `json:"bans"`
+}
+
+// SendRestrictions is what decides whether a user may post in a room right now.
+type SendRestrictions struct {
+	Banned        bool
+	MutedUntil    *time.Time
+	SlowMode      int
+	LastMessageAt *time.Time
+}
@@ -1,11 +1,58 @@
 package models
 
-import "time"
+import (
+	"errors"
+	"time"
+
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/text"
+)
+
+// DeletedPlaceholder replaces the content of a deleted comment that still has replies.
+const DeletedPlaceholder = "[deleted]"
+
+var (
+	ErrNotFound       = errors.New("comment not found")
+	ErrParentNotFound = errors.New("parent comment not found")
+	ErrMaxDepth       = errors.New("maximum reply depth reached")
+	ErrPostNotFound   = errors.New("post not found")
+	ErrPostLocked     = errors.New("post is locked")
+	ErrPostArchived   = errors.New("post is archived")
+)
 
 type Comment struct {
-	ID        int       `db:"id" json:"id"`
-	PostID    int       `db:"post_id" json:"postId"`
-	Username  string    `db:"username" json:"username"`
-	Content   string    `db:"content" json:"content"`
-	Timestamp time.Time `db:"timestamp" json:"timestamp"`
+	ID        int            `db:"id" json:"id"`
+	PostID    int            `db:"post_id" json:"postId"`
+	ParentID  *int           `db:"parent_id" json:"parentId,omitempty"`
+	Depth     int            `db:"depth" json:"depth"`
+	Username  string         `db:"username" json:"username"`
+	Content   string         `db:"content" json:"content"`
+	Deleted   bool           `db:"is_deleted" json:"deleted,omitempty"`
+	Reactions map[string]int `db:"-" json:"reactions"`          // emoji to number of users
+	Accepted  bool           `db:"-" json:"accepted,omitempty"` // accepted as the answer to its post
+	Timestamp time.Time      `db:"timestamp" json:"timestamp"`
+	Replies   []*Comment     `db:"-" json:"replies,omitempty"`
+}
+
+// Preview returns a copy of the comment with its content cut to n characters.
+func (c Comment) Preview(n int) Comment {
+	c.Content = text.Excerpt(c.Content, n)
+	return c
+}
+
+// BuildTree nests a thread listed in path order (parents before replies) into a tree.
+func BuildTree(flat []Comment) []*Comment {
+	nodes := make(map[int]*Comment, len(flat))
+	var roots []*Comment
+	for i := range flat {
+		c := &flat[i]
+		nodes[c.ID] = c
+		if c.ParentID == nil {
+			roots = append(roots, c)
+			continue
+		}
+		if parent, ok := nodes[*c.ParentID]; ok {
+			parent.Replies = append(parent.Replies, c)
+		}
+	}
+	return roots
 }
@@ -0,0 +1,58 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// ErrTooFarBehind is returned to a resuming client that missed more than can
+// be replayed; it has to refetch the history instead.
+var ErrTooFarBehind = errors.New("too far behind, refetch history")
+
+// Event types published on the shared event channel.
+const (
+	ChatMessage         = "chat.message"
+	ChatMessageEdited   = "chat.message.edited"
+	ChatMessageDeleted  = "chat.message.deleted"
+	ChatMessageReaction = "chat.message.reaction"
+	DirectMessage       = "chat.dm"
+	ChatTypingStart     = "chat.typing.start"
+	ChatTypingStop      = "chat.typing.stop"
+	ChatPresenceJoin    = "chat.presence.join"
+	ChatPresenceLeave   = "chat.presence.leave"
+	ChatRoomUpdated     = "chat.room.updated"
+	ChatUserMuted       = "chat.moderation.mute"
+	ChatUserBanned      = "chat.moderation.ban"
+	PostCreated         = "forum.post.created"
+	CommentCreated      = "forum.comment.created"
+	Notification        = "user.notification"
+)
+
+// Event is a notification fanned out to every server instance.
+type Event struct {
+	Type    string          `json:"type"`
+	ID      int             `json:"id,omitempty"` // id of the entity the event is about
+	RoomID  int             `json:"room_id,omitempty"`
+	UserIDs []int32         `json:"user_ids,omitempty"` // recipients of user-addressed events
+	Payload json.RawMessage `json:"payload"`
+}
+
+// ExcerptLength bounds user text, such as a post title or a comment, carried
+// by forum events and notifications. Events have to fit into a NOTIFY payload.
+const ExcerptLength = 500
+
+// Publisher delivers events to every server instance, including this one.
+type Publisher interface {
+	Publish(ctx context.Context, e Event) error
+}
+
+// Publish encodes payload as JSON and publishes it as an event of the given
+// type about the entity with the given id.
+func Publish(ctx context.Context, p Publisher, eventType string, id int, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return p.Publish(ctx, Event{Type: eventType, ID: id, Payload: data})
+}
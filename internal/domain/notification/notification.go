@@ -0,0 +1,60 @@
+package notification
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidTarget  = errors.New("subscription needs exactly one of topic_id and post_id")
+	ErrTargetNotFound = errors.New("topic or post not found")
+)
+
+// Kinds of notifications.
+const (
+	KindComment = "comment" // someone commented on a post the recipient wrote or watches
+	KindReply   = "reply"   // someone replied to the recipient's comment
+	KindPost    = "post"    // a new post in a topic the recipient follows
+	KindMention = "mention" // someone mentioned the recipient as @username
+)
+
+// Notification tells a user about forum activity that concerns them. It
+// points either at a post, and possibly one of its comments, or at a chat
+// message.
+type Notification struct {
+	ID        int        `json:"id"`
+	UserID    int32      `json:"user_id"`
+	Kind      string     `json:"kind"`
+	Actor     string     `json:"actor"` // username of whoever caused it
+	PostID    *int       `json:"post_id,omitempty"`
+	CommentID *int       `json:"comment_id,omitempty"`
+	RoomID    *int       `json:"room_id,omitempty"`
+	MessageID *int       `json:"message_id,omitempty"`
+	Title     string     `json:"title"` // title of the post, empty for chat messages
+	Excerpt   string     `json:"excerpt"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	Timestamp time.Time  `json:"timestamp"`
+}
+
+// Mention records that a post, comment or chat message mentioned a user.
+// Its source is set like a Notification's.
+type Mention struct {
+	ID        int       `json:"id"`
+	UserID    int32     `json:"user_id"`
+	Username  string    `json:"username"` // the mentioned user
+	Actor     string    `json:"actor"`    // who wrote the mention
+	PostID    *int      `json:"post_id,omitempty"`
+	CommentID *int      `json:"comment_id,omitempty"`
+	RoomID    *int      `json:"room_id,omitempty"`
+	MessageID *int      `json:"message_id,omitempty"`
+	Excerpt   string    `json:"excerpt"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Subscription makes a user hear about new posts in a topic or new comments
+// on a post. Exactly one of TopicID and PostID is set.
+type Subscription struct {
+	TopicID   *int      `json:"topic_id,omitempty"`
+	PostID    *int      `json:"post_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
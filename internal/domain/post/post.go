@@ -1,12 +1,96 @@
 package post
 
-import "time"
+import (
+	"errors"
+	"time"
+
+	models "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/comment"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/topic"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/text"
+)
+
+var (
+	ErrNotFound         = errors.New("post not found")
+	ErrForbidden        = errors.New("not allowed to modify post")
+	ErrRevisionNotFound = errors.New("post revision not found")
+	ErrNotQA            = errors.New("post is not in a Q&A topic")
+	ErrAnswerNotFound   = errors.New("comment not found on this post")
+	ErrArchived         = errors.New("post is archived")
+	ErrDiffTooLarge     = errors.New("revisions differ in too many lines to diff")
+)
 
 type Post struct {
+	ID        int            `json:"id"`
+	TopicID   int            `json:"topic_id"`
+	Title     string         `json:"title"`
+	Content   string         `json:"content"`
+	Username  string         `json:"username"`
+	Tags      []string       `json:"tags"`
+	Reactions map[string]int `json:"reactions"` // emoji to number of users
+
+	AcceptedCommentID *int `json:"accepted_comment_id,omitempty"` // answer accepted in a Q&A topic
+
+	Pinned   bool `json:"pinned,omitempty"`   // listed first in its topic
+	Locked   bool `json:"locked,omitempty"`   // takes no new comments
+	Archived bool `json:"archived,omitempty"` // read-only and left out of lists
+
+	Timestamp time.Time  `json:"timestamp"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// Filter narrows a list of posts. Zero-valued fields are not applied.
+type Filter struct {
+	TopicID  *int
+	Tag      string
+	Answered *bool // with true, posts that have an accepted answer; with false, questions in Q&A topics that have none
+	Archived bool  // list archived posts instead of the others
+}
+
+// State changes the moderation state of a post. Nil fields are left as they are.
+type State struct {
+	Pinned   *bool `json:"pinned"`
+	Locked   *bool `json:"locked"`
+	Archived *bool `json:"archived"`
+}
+
+// Preview returns a copy of the post with its title and content cut to n
+// characters each.
+func (p Post) Preview(n int) Post {
+	p.Title = text.Excerpt(p.Title, n)
+	p.Content = text.Excerpt(p.Content, n)
+	return p
+}
+
+// Details is a single post together with its topic and the first page of
+// its comment tree. Further pages come from GET /comments/thread.
+type Details struct {
+	Post               Post              `json:"post"`
+	Topic              topic.Topic       `json:"topic"`
+	Comments           []*models.Comment `json:"comments"`
+	CommentsNextCursor string            `json:"comments_next_cursor,omitempty"`
+}
+
+// Revision is a stored version of a post. Version 1 is the original text.
+type Revision struct {
 	ID        int       `json:"id"`
-	TopicID   int       `json:"topic_id"`
+	PostID    int       `json:"post_id"`
+	Version   int       `json:"version"`
 	Title     string    `json:"title"`
 	Content   string    `json:"content"`
 	Username  string    `json:"username"`
 	Timestamp time.Time `json:"timestamp"`
 }
+
+// DiffLine is one line of a line-based diff. Op is "=", "+" or "-".
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+type RevisionDiff struct {
+	PostID  int        `json:"post_id"`
+	From    int        `json:"from"`
+	To      int        `json:"to"`
+	Title   []DiffLine `json:"title"`
+	Content []DiffLine `json:"content"`
+}
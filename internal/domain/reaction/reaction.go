@@ -0,0 +1,61 @@
+package reaction
+
+import (
+	"errors"
+	"slices"
+)
+
+var (
+	ErrInvalidTarget  = errors.New("reaction needs exactly one of post_id, comment_id and message_id")
+	ErrInvalidEmoji   = errors.New("emoji is not one of the allowed reactions")
+	ErrTargetNotFound = errors.New("post, comment or message not found")
+	ErrForbidden      = errors.New("not allowed to react in this room")
+	ErrArchived       = errors.New("post is archived")
+)
+
+// Emojis are the reactions users can pick from.
+var Emojis = []string{"👍", "👎", "❤️", "😂", "😮", "😢", "🎉", "🚀"}
+
+// Valid reports whether emoji is one of Emojis.
+func Valid(emoji string) bool {
+	return slices.Contains(Emojis, emoji)
+}
+
+// Target is what a reaction is on: exactly one of the ids is set.
+type Target struct {
+	PostID    *int `json:"post_id,omitempty"`
+	CommentID *int `json:"comment_id,omitempty"`
+	MessageID *int `json:"message_id,omitempty"`
+}
+
+// Valid reports whether exactly one id is set.
+func (t Target) Valid() bool {
+	n := 0
+	for _, id := range []*int{t.PostID, t.CommentID, t.MessageID} {
+		if id != nil {
+			n++
+		}
+	}
+	return n == 1
+}
+
+// Counts maps each emoji used on a target to how many users picked it.
+type Counts map[string]int
+
+// Reaction lists who reacted to a target with one emoji, earliest first.
+type Reaction struct {
+	Emoji     string   `json:"emoji"`
+	Count     int      `json:"count"`
+	Usernames []string `json:"usernames"`
+}
+
+// Toggled is the outcome of toggling a reaction. Reacted tells whether the
+// user now has the reaction; Reactions holds the target's updated counts.
+type Toggled struct {
+	Target
+	RoomID    int    `json:"room_id,omitempty"` // set for chat messages
+	Username  string `json:"username"`
+	Emoji     string `json:"emoji"`
+	Reacted   bool   `json:"reacted"`
+	Reactions Counts `json:"reactions"`
+}
@@ -0,0 +1,41 @@
+package search
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrEmptyQuery  = errors.New("search query is empty")
+	ErrInvalidType = errors.New("search type must be post or comment")
+)
+
+// Result types.
+const (
+	TypePost    = "post"
+	TypeComment = "comment"
+)
+
+// Query describes a search. Zero-valued filters are not applied.
+type Query struct {
+	Text    string
+	Type    string // TypePost, TypeComment or empty for both
+	TopicID *int
+	Author  string
+	From    *time.Time // inclusive
+	To      *time.Time // exclusive
+}
+
+// Result is a post or comment matching a search. Headline is an HTML-escaped
+// excerpt of its content with the matched words wrapped in <mark></mark>.
+type Result struct {
+	Type      string    `json:"type"`
+	ID        int       `json:"id"`
+	PostID    int       `json:"post_id"`
+	TopicID   int       `json:"topic_id"`
+	Title     string    `json:"title"` // title of the post
+	Headline  string    `json:"headline"`
+	Username  string    `json:"username"`
+	Timestamp time.Time `json:"timestamp"`
+	Rank      float32   `json:"rank"`
+}
@@ -0,0 +1,38 @@
+package tag
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrInvalid    = errors.New("invalid tag")
+	ErrTooMany    = errors.New("too many tags")
+	ErrNotAllowed = errors.New("tag is not on the allowlist")
+	ErrExists     = errors.New("tag already exists")
+	ErrNotFound   = errors.New("tag not found")
+)
+
+// MaxPerPost is how many tags a single post may carry.
+const MaxPerPost = 5
+
+// namePattern allows names such as "go", "c++", "c#" and "generics-1.18".
+var namePattern = regexp.MustCompile(`^[\p{L}\p{N}][\p{L}\p{N}+#._-]{0,31}$`)
+
+// Tag is a label on posts. Count is the number of listed posts carrying it,
+// leaving out archived and trashed ones.
+type Tag struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// Normalize lowercases a tag and joins its words with dashes, so that
+// "Error Handling" and "error-handling" are the same tag.
+func Normalize(name string) (string, error) {
+	name = strings.Join(strings.Fields(strings.ToLower(name)), "-")
+	if !namePattern.MatchString(name) {
+		return "", ErrInvalid
+	}
+	return name, nil
+}
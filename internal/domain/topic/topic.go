@@ -1,7 +1,16 @@
 package topic
 
+import (
+	"errors"
+	"time"
+)
+
+var ErrNotFound = errors.New("topic not found")
+
 type Topic struct {
-	ID          int    `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
+	ID          int       `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	QA          bool      `json:"is_qa"` // posts are questions that can have an accepted answer
+	CreatedAt   time.Time `json:"created_at"`
 }
@@ -0,0 +1,42 @@
+package trash
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNotFound      = errors.New("not in the trash")
+	ErrParentDeleted = errors.New("parent is in the trash")
+	ErrInvalidType   = errors.New("invalid trash item type")
+)
+
+// Types of items in the trash.
+const (
+	TypeTopic   = "topic"
+	TypePost    = "post"
+	TypeComment = "comment"
+)
+
+// Item is a deleted topic, post or comment. Items deleted together with
+// their topic or post are not listed on their own; restoring the topic or
+// post brings them back.
+type Item struct {
+	Type      string    `json:"type"`
+	ID        int       `json:"id"`
+	TopicID   *int      `json:"topic_id,omitempty"`
+	PostID    *int      `json:"post_id,omitempty"`
+	Title     string    `json:"title"` // topic or post title; for comments, the title of their post
+	Excerpt   string    `json:"excerpt"`
+	Username  string    `json:"username,omitempty"` // author; empty for topics
+	DeletedAt time.Time `json:"deleted_at"`
+	DeletedBy string    `json:"deleted_by"`
+	PurgeAt   time.Time `json:"purge_at"` // when the purge job removes it for good
+}
+
+// Purged counts what one purge removed.
+type Purged struct {
+	Topics   int64
+	Posts    int64
+	Comments int64
+}
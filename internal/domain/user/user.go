@@ -0,0 +1,17 @@
+package user
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrNotFound = errors.New("user not found")
+
+// User is a local copy of an account from the auth service, recorded the
+// first time its token is validated so other features can refer to it.
+type User struct {
+	ID         int32     `json:"id"`
+	Username   string    `json:"username"`
+	Role       string    `json:"role"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
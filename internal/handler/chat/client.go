@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/config"
+	domain "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/chat"
+	"github.com/gorilla/websocket"
+)
+
+// typingRefresh is how often a client that keeps typing re-announces it.
+// Repeated typing.start frames within this interval are not forwarded.
+const typingRefresh = 3 * time.Second
+
+// Client is one WebSocket connection subscribed to a room.
+type Client struct {
+	hub      *Hub
+	conn     *websocket.Conn
+	cfg      config.Chat
+	send     chan frame
+	roomID   int
+	userID   int32
+	username string
+	role     string
+
+	// typing and typingSince track typing notices sent by this client; they
+	// are only touched by the read loop.
+	typing      bool
+	typingSince time.Time
+
+	// forum is set for clients that also receive new posts and comments.
+	forum bool
+
+	// replayed holds the items written while replaying history on resume;
+	// live frames for them are duplicates. Ids are not handed out in commit
+	// order, so a lower id than the last replayed one may still be new.
+	// Filled before the writer starts.
+	replayed map[position]struct{}
+}
+
+func newClient(hub *Hub, conn *websocket.Conn, cfg config.Chat, roomID int, userID int32, username, role string) *Client {
+	return &Client{
+		hub:      hub,
+		conn:     conn,
+		cfg:      cfg,
+		send:     make(chan frame, cfg.SendBuffer),
+		roomID:   roomID,
+		userID:   userID,
+		username: username,
+		role:     role,
+		replayed: make(map[position]struct{}),
+	}
+}
+
+// readPump passes every incoming frame to handle until the connection fails
+// or the peer stops answering pings, then unregisters the client.
+func (c *Client) readPump(handle func(c *Client, data []byte)) {
+	defer func() {
+		c.hub.Unregister(c)
+		c.conn.Close()
+	}()
+
+	c.conn.SetReadLimit(c.cfg.MaxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(c.cfg.PongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(c.cfg.PongWait))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		c.conn.SetReadDeadline(time.Now().Add(c.cfg.PongWait))
+		handle(c, data)
+	}
+}
+
+// replay writes missed messages straight to the connection. It must run
+// before writePump starts, while the client is already registered so that
+// live messages queue up behind the replay instead of being lost.
+func (c *Client) replay(messages []domain.ChatMessage) error {
+	for _, msg := range messages {
+		data, err := newFrame(frameMessage, strconv.Itoa(msg.ID), msg)
+		if err != nil {
+			return err
+		}
+		if err := c.write(data); err != nil {
+			return err
+		}
+		c.replayed[position{Message: msg.ID}] = struct{}{}
+	}
+	return nil
+}
+
+// write sends data straight to the connection. Like replay, it may only be
+// used before writePump starts.
+func (c *Client) write(data []byte) error {
+	c.conn.SetWriteDeadline(time.Now().Add(c.cfg.WriteWait))
+	return c.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// writePump is the only goroutine writing to the connection. It sends queued
+// frames and periodic pings, and exits when the hub closes the send queue or
+// a write fails.
+func (c *Client) writePump() {
+	ticker := time.NewTicker(c.cfg.PingInterval)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case f, ok := <-c.send:
+			if _, dup := c.replayed[f.pos]; ok && dup {
+				continue
+			}
+			c.conn.SetWriteDeadline(time.Now().Add(c.cfg.WriteWait))
+			if !ok {
+				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, f.data); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(c.cfg.WriteWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	domain "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/chat"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type SendDirectInput struct {
+	To      string `json:"to" binding:"required"`
+	Content string `json:"content" binding:"required"`
+}
+
+// InboxHandler godoc
+// @Summary List direct message conversations of the current user
+// @Tags Direct messages
+// @Produce json
+// @Success 200 {array} domain.Conversation
+// @Failure 401,500 {object} response.ErrorResponse
+// @Router /chat/dm/inbox [get]
+func (h *ChatHandler) InboxHandler(c *gin.Context) {
+	inbox, err := h.usecase.GetInbox(c.Request.Context(), middleware.CurrentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get inbox"})
+		return
+	}
+	c.JSON(http.StatusOK, inbox)
+}
+
+// UnreadDirectHandler godoc
+// @Summary Count unread direct messages of the current user
+// @Tags Direct messages
+// @Produce json
+// @Success 200 {object} response.UnreadResponse
+// @Failure 401,500 {object} response.ErrorResponse
+// @Router /chat/dm/unread [get]
+func (h *ChatHandler) UnreadDirectHandler(c *gin.Context) {
+	n, err := h.usecase.CountUnreadDirect(c.Request.Context(), middleware.CurrentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count unread messages"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"unread": n})
+}
+
+// DirectMessagesHandler godoc
+// @Summary Get the direct conversation with a user
+// @Tags Direct messages
+// @Produce json
+// @Param user query string true "Username of the other participant"
+// @Param since_id query int false "Only messages after this id, oldest first"
+// @Param before_id query int false "Only messages before this id"
+// @Param limit query int false "Maximum number of messages"
+// @Success 200 {array} domain.DirectMessage
+// @Failure 400,401,404,500 {object} response.ErrorResponse
+// @Router /chat/dm/messages [get]
+func (h *ChatHandler) DirectMessagesHandler(c *gin.Context) {
+	sinceID, errSince := queryInt(c, "since_id")
+	beforeID, errBefore := queryInt(c, "before_id")
+	limit, errLimit := queryInt(c, "limit")
+	if errSince != nil || errBefore != nil || errLimit != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid since_id, before_id or limit"})
+		return
+	}
+
+	q := domain.HistoryQuery{SinceID: sinceID, BeforeID: beforeID, Limit: limit}
+	msgs, err := h.usecase.GetDirectMessages(c.Request.Context(), middleware.CurrentUserID(c), c.Query("user"), q)
+	switch {
+	case errors.Is(err, domain.ErrInvalidPeer):
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get messages"})
+		return
+	}
+	c.JSON(http.StatusOK, msgs)
+}
+
+// SendDirectHandler godoc
+// @Summary Send a direct message
+// @Description The message is also delivered live to both participants over the chat WebSocket.
+// @Tags Direct messages
+// @Accept json
+// @Produce json
+// @Param message body SendDirectInput true "Recipient username and content"
+// @Success 201 {object} domain.DirectMessage
+// @Failure 400,401,404,500 {object} response.ErrorResponse
+// @Router /chat/dm/send [post]
+func (h *ChatHandler) SendDirectHandler(c *gin.Context) {
+	var input SendDirectInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+
+	msg, err := h.usecase.SendDirectMessage(c.Request.Context(), middleware.CurrentUserID(c), input.To, input.Content)
+	switch {
+	case errors.Is(err, domain.ErrInvalidPeer):
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	case errors.Is(err, domain.ErrMessageTooLong):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "message too long"})
+		return
+	case err != nil && msg.ID == 0:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message"})
+		return
+	}
+	c.JSON(http.StatusCreated, msg)
+}
+
+// MarkDirectReadHandler godoc
+// @Summary Mark the conversation with a user as read
+// @Tags Direct messages
+// @Produce json
+// @Param user query string true "Username of the other participant"
+// @Success 200 {object} response.MessageResponse
+// @Failure 401,404,500 {object} response.ErrorResponse
+// @Router /chat/dm/read [post]
+func (h *ChatHandler) MarkDirectReadHandler(c *gin.Context) {
+	_, err := h.usecase.MarkDirectRead(c.Request.Context(), middleware.CurrentUserID(c), c.Query("user"))
+	switch {
+	case errors.Is(err, domain.ErrInvalidPeer):
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark messages read"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "messages marked read"})
+}
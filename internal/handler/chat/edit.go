@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	domain "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/chat"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type EditMessageInput struct {
+	Content string `json:"content" binding:"required"`
+}
+
+// EditMessageHandler godoc
+// @Summary Edit a chat message
+// @Description Only the author can edit a text message, within CHAT_EDIT_WINDOW of sending it and not while muted or banned. Connected clients receive a message.edited frame.
+// @Tags Chat
+// @Accept json
+// @Produce json
+// @Param message_id query int true "Message ID"
+// @Param message body EditMessageInput true "New content"
+// @Success 200 {object} domain.ChatMessage
+// @Failure 400,401,403,404,500 {object} response.ErrorResponse
+// @Router /chat/messages/edit [put]
+func (h *ChatHandler) EditMessageHandler(c *gin.Context) {
+	messageID, err := strconv.Atoi(c.Query("message_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid message_id"})
+		return
+	}
+
+	var input EditMessageInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+
+	msg, err := h.usecase.EditMessage(c.Request.Context(), messageID, middleware.CurrentUserID(c), c.GetString("username"), input.Content)
+	if msg.ID == 0 {
+		status, text := messageErrorStatus(err)
+		c.JSON(status, gin.H{"error": text})
+		return
+	}
+	c.JSON(http.StatusOK, msg)
+}
+
+// DeleteMessageHandler godoc
+// @Summary Delete a chat message
+// @Description Authors can delete their messages within CHAT_EDIT_WINDOW; ADMINs can delete any message. Connected clients receive a message.deleted frame.
+// @Tags Chat
+// @Produce json
+// @Param message_id query int true "Message ID"
+// @Success 200 {object} response.MessageResponse
+// @Failure 400,401,403,404,500 {object} response.ErrorResponse
+// @Router /chat/messages/delete [delete]
+func (h *ChatHandler) DeleteMessageHandler(c *gin.Context) {
+	messageID, err := strconv.Atoi(c.Query("message_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid message_id"})
+		return
+	}
+
+	deletion, err := h.usecase.DeleteMessage(c.Request.Context(), messageID, c.GetString("username"), c.GetString("role"))
+	if deletion.ID == 0 {
+		status, text := messageErrorStatus(err)
+		c.JSON(status, gin.H{"error": text})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "message deleted"})
+}
+
+// messageErrorStatus maps errors of message edits and deletions to a response.
+func messageErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, domain.ErrMessageNotFound):
+		return http.StatusNotFound, "message not found"
+	case errors.Is(err, domain.ErrRoomNotFound):
+		return http.StatusNotFound, "room not found"
+	case errors.Is(err, domain.ErrForbidden):
+		return http.StatusForbidden, "only the author or admin can change this message"
+	case errors.Is(err, domain.ErrEditWindow):
+		return http.StatusForbidden, "message can no longer be changed"
+	case errors.Is(err, domain.ErrNotEditable):
+		return http.StatusBadRequest, "only text messages can be edited"
+	case errors.Is(err, domain.ErrMessageTooLong):
+		return http.StatusBadRequest, "message too long"
+	case errors.Is(err, domain.ErrBanned), errors.Is(err, domain.ErrMuted):
+		return http.StatusForbidden, err.Error()
+	default:
+		return http.StatusInternalServerError, "Failed to change message"
+	}
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	domain "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/chat"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/event"
+)
+
+// protocolVersion is the version of the frame envelope. Frames from clients
+// that omit it are read as the current version.
+const protocolVersion = 1
+
+// Frame types exchanged over the chat WebSocket.
+const (
+	frameMessage = "message" // public room message, both directions
+
+	frameMessageEdit     = "message.edit"     // client asks to edit its message
+	frameMessageDelete   = "message.delete"   // client asks to delete a message
+	frameMessageEdited   = "message.edited"   // a message's content changed
+	frameMessageDeleted  = "message.deleted"  // a message was removed
+	frameMessageReaction = "message.reaction" // a user added or removed a reaction to a message
+	frameDirect          = "dm"               // private message, both directions
+
+	frameTypingStart = "typing.start" // both directions
+	frameTypingStop  = "typing.stop"  // both directions
+
+	framePresenceJoin     = "presence.join"     // a user came online in the room
+	framePresenceLeave    = "presence.leave"    // a user's last connection to the room closed
+	framePresenceSnapshot = "presence.snapshot" // everyone online, sent once after connecting
+
+	frameRoomUpdated = "room.updated"      // room settings such as slow mode changed
+	frameMuted       = "moderation.muted"  // sent to a user who was muted
+	frameBanned      = "moderation.banned" // sent to a banned user right before the socket closes
+
+	framePostCreated    = "post.created"    // a forum post was created
+	frameCommentCreated = "comment.created" // a forum comment was created
+	frameNotification   = "notification"    // sent to the user a notification is for
+
+	frameAck   = "ack"   // a client frame with an id was handled
+	frameError = "error" // a client frame could not be handled
+)
+
+// Frame is the envelope of every WebSocket message. Clients may set ID on
+// their frames; the ack or error reply carries the same ID. Server frames
+// about a stored message carry the message id. Client frames without a type
+// are treated as room messages for compatibility with older clients.
+type Frame struct {
+	V       int             `json:"v"`
+	Type    string          `json:"type"`
+	ID      string          `json:"id,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+type messagePayload struct {
+	Content string `json:"content"`
+}
+
+// editPayload is sent with message.edit and message.delete; Content is
+// ignored for deletions.
+type editPayload struct {
+	ID      int    `json:"id"`
+	Content string `json:"content"`
+}
+
+type directPayload struct {
+	To      string `json:"to"` // recipient username
+	Content string `json:"content"`
+}
+
+type snapshotPayload struct {
+	RoomID int               `json:"room_id"`
+	Online []domain.Presence `json:"online"`
+}
+
+// ackPayload identifies what a handled frame created, if anything. Notice is
+// feedback from a slash command meant only for the sender.
+type ackPayload struct {
+	MessageID int    `json:"message_id,omitempty"`
+	Notice    string `json:"notice,omitempty"`
+}
+
+type errorPayload struct {
+	Error string `json:"error"`
+}
+
+// tooFarBehindFrame tells a resuming client that it missed more than is
+// replayed and has to refetch the history over REST.
+func tooFarBehindFrame() []byte {
+	data, _ := newFrame(frameError, "", errorPayload{Error: event.ErrTooFarBehind.Error()})
+	return data
+}
+
+// encodeFrame wraps an already encoded payload in an envelope.
+func encodeFrame(frameType, id string, payload json.RawMessage) []byte {
+	data, _ := json.Marshal(Frame{V: protocolVersion, Type: frameType, ID: id, Payload: payload})
+	return data
+}
+
+// newFrame encodes payload and wraps it in an envelope.
+func newFrame(frameType, id string, payload any) ([]byte, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return encodeFrame(frameType, id, raw), nil
+}
+
+// position is how far a client has received each resumable stream. It is
+// sent as the SSE event id, "<message>-<post>-<comment>", and comes back in
+// Last-Event-ID when the client reconnects.
+type position struct {
+	Message int
+	Post    int
+	Comment int
+}
+
+func (p position) String() string {
+	return fmt.Sprintf("%d-%d-%d", p.Message, p.Post, p.Comment)
+}
+
+func parsePosition(s string) (position, error) {
+	var p position
+	_, err := fmt.Sscanf(s, "%d-%d-%d", &p.Message, &p.Post, &p.Comment)
+	if err != nil || p.Message < 0 || p.Post < 0 || p.Comment < 0 {
+		return position{}, fmt.Errorf("invalid event id %q", s)
+	}
+	return p, nil
+}
+
+// isZero reports whether the position carries no stream ids.
+func (p position) isZero() bool {
+	return p == position{}
+}
+
+// advance moves p past the item identified by q.
+func (p *position) advance(q position) {
+	p.Message = max(p.Message, q.Message)
+	p.Post = max(p.Post, q.Post)
+	p.Comment = max(p.Comment, q.Comment)
+}
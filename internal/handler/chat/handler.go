@@ -3,47 +3,145 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/AdminGo/proto/authpb"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/config"
 	domain "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/chat"
+	models "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/comment"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/event"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/post"
 	chatUsecase "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/usecase/chat"
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 	"go.uber.org/zap"
 )
 
+// PostFeed supplies new posts to live clients that reconnect.
+type PostFeed interface {
+	PostsSince(ctx context.Context, afterID int) ([]post.Post, error)
+	LatestPostID(ctx context.Context) (int, error)
+}
+
+// CommentFeed supplies new comments to live clients that reconnect.
+type CommentFeed interface {
+	CommentsSince(ctx context.Context, afterID int) ([]models.Comment, error)
+	LatestCommentID(ctx context.Context) (int, error)
+}
+
 type ChatHandler struct {
 	usecase     *chatUsecase.UseCase
+	posts       PostFeed
+	comments    CommentFeed
 	authService authpb.AuthServiceClient
-	clients     map[*websocket.Conn]bool
-	broadcast   chan domain.ChatMessage
+	hub         *Hub
+	cfg         config.Chat
 	logger      *zap.Logger
 }
 
-func New(usecase *chatUsecase.UseCase, authService authpb.AuthServiceClient, logger *zap.Logger) *ChatHandler {
+func New(usecase *chatUsecase.UseCase, posts PostFeed, comments CommentFeed, authService authpb.AuthServiceClient, cfg config.Chat, logger *zap.Logger) *ChatHandler {
 	h := &ChatHandler{
 		usecase:     usecase,
+		posts:       posts,
+		comments:    comments,
 		authService: authService,
-		clients:     make(map[*websocket.Conn]bool),
-		broadcast:   make(chan domain.ChatMessage),
+		hub:         NewHub(logger),
+		cfg:         cfg,
 		logger:      logger,
 	}
-	go h.handleMessages()
 	return h
 }
 
+// Run serves connected clients until ctx is cancelled.
+func (h *ChatHandler) Run(ctx context.Context) {
+	h.hub.Run(ctx)
+}
+
+// eventFrames maps the event types delivered to chat clients to frame types.
+var eventFrames = map[string]string{
+	event.ChatMessage:         frameMessage,
+	event.ChatMessageEdited:   frameMessageEdited,
+	event.ChatMessageDeleted:  frameMessageDeleted,
+	event.ChatMessageReaction: frameMessageReaction,
+	event.DirectMessage:       frameDirect,
+	event.ChatTypingStart:     frameTypingStart,
+	event.ChatTypingStop:      frameTypingStop,
+	event.ChatPresenceJoin:    framePresenceJoin,
+	event.ChatPresenceLeave:   framePresenceLeave,
+	event.ChatRoomUpdated:     frameRoomUpdated,
+	event.ChatUserMuted:       frameMuted,
+	event.ChatUserBanned:      frameBanned,
+	event.PostCreated:         framePostCreated,
+	event.CommentCreated:      frameCommentCreated,
+	event.Notification:        frameNotification,
+}
+
+// Dispatch delivers an event received from the event channel to local clients.
+func (h *ChatHandler) Dispatch(e event.Event) {
+	frameType, ok := eventFrames[e.Type]
+	if !ok {
+		return
+	}
+
+	var id string
+	if e.ID != 0 {
+		id = strconv.Itoa(e.ID)
+	}
+
+	switch e.Type {
+	case event.ChatMessage:
+		h.hub.Broadcast(e.RoomID, position{Message: e.ID}, encodeFrame(frameType, id, e.Payload))
+	case event.DirectMessage, event.ChatUserMuted, event.Notification:
+		h.hub.SendToUsers(e.UserIDs, encodeFrame(frameType, id, e.Payload))
+	case event.ChatUserBanned:
+		h.hub.Kick(e.RoomID, e.UserIDs, encodeFrame(frameType, id, e.Payload))
+	case event.PostCreated:
+		h.hub.Announce(position{Post: e.ID}, encodeFrame(frameType, id, e.Payload))
+	case event.CommentCreated:
+		h.hub.Announce(position{Comment: e.ID}, encodeFrame(frameType, id, e.Payload))
+	default:
+		h.hub.Broadcast(e.RoomID, position{}, encodeFrame(frameType, id, e.Payload))
+	}
+}
+
+type CreateRoomInput struct {
+	Name  string `json:"name" binding:"required"`
+	Title string `json:"title"`
+}
+
 // GetMessagesHandler godoc
-// @Summary Get recent chat messages
+// @Summary Get chat messages of a room
+// @Description Without since_id/before_id returns the newest messages. Results are always in ascending id order.
 // @Tags Chat
 // @Produce json
+// @Param room query string false "Room name (default: general)"
+// @Param since_id query int false "Only messages after this id, oldest first"
+// @Param before_id query int false "Only messages before this id"
+// @Param limit query int false "Maximum number of messages"
 // @Success 200 {array} domain.ChatMessage
-// @Failure 500 {object} response.ErrorResponse
+// @Failure 400,404,500 {object} response.ErrorResponse
 // @Router /chat/messages [get]
 func (h *ChatHandler) GetMessagesHandler(c *gin.Context) {
-	messages, err := h.usecase.GetMessages(c.Request.Context())
+	sinceID, errSince := queryInt(c, "since_id")
+	beforeID, errBefore := queryInt(c, "before_id")
+	limit, errLimit := queryInt(c, "limit")
+	if errSince != nil || errBefore != nil || errLimit != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid since_id, before_id or limit"})
+		return
+	}
+
+	room, ok := h.resolveRoom(c)
+	if !ok {
+		return
+	}
+
+	q := domain.HistoryQuery{SinceID: sinceID, BeforeID: beforeID, Limit: limit}
+	messages, err := h.usecase.GetMessages(c.Request.Context(), room.ID, q)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get messages"})
 		return
@@ -51,25 +149,184 @@ func (h *ChatHandler) GetMessagesHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, messages)
 }
 
+// ListRoomsHandler godoc
+// @Summary List chat rooms
+// @Tags Chat
+// @Produce json
+// @Success 200 {array} domain.Room
+// @Failure 500 {object} response.ErrorResponse
+// @Router /chat/rooms [get]
+func (h *ChatHandler) ListRoomsHandler(c *gin.Context) {
+	rooms, err := h.usecase.ListRooms(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get rooms"})
+		return
+	}
+	c.JSON(http.StatusOK, rooms)
+}
+
+// CreateRoomHandler godoc
+// @Summary Create an ad-hoc chat room
+// @Tags Chat
+// @Accept json
+// @Produce json
+// @Param room body CreateRoomInput true "Room name and title"
+// @Success 201 {object} domain.Room
+// @Failure 400,401,409,500 {object} response.ErrorResponse
+// @Router /chat/rooms [post]
+func (h *ChatHandler) CreateRoomHandler(c *gin.Context) {
+	var input CreateRoomInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+
+	room, err := h.usecase.CreateRoom(c.Request.Context(), input.Name, input.Title, c.GetString("username"))
+	switch {
+	case errors.Is(err, domain.ErrInvalidRoomName):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "room name must be 2-64 lowercase letters, digits, '-' or '_'"})
+		return
+	case errors.Is(err, domain.ErrRoomExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "room already exists"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create room"})
+		return
+	}
+	c.JSON(http.StatusCreated, room)
+}
+
+// GetMembersHandler godoc
+// @Summary List users who have joined a chat room
+// @Tags Chat
+// @Produce json
+// @Param room query string false "Room name (default: general)"
+// @Success 200 {array} domain.Member
+// @Failure 404,500 {object} response.ErrorResponse
+// @Router /chat/rooms/members [get]
+func (h *ChatHandler) GetMembersHandler(c *gin.Context) {
+	room, ok := h.resolveRoom(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.usecase.GetMembers(c.Request.Context(), room.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get members"})
+		return
+	}
+	c.JSON(http.StatusOK, members)
+}
+
+// resolveRoom looks up the room from the "room" query parameter and writes
+// an error response if it cannot be found.
+func (h *ChatHandler) resolveRoom(c *gin.Context) (domain.Room, bool) {
+	room, err := h.usecase.ResolveRoom(c.Request.Context(), c.Query("room"))
+	switch {
+	case errors.Is(err, domain.ErrRoomNotFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "room not found"})
+		return domain.Room{}, false
+	case err != nil:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to get room"})
+		return domain.Room{}, false
+	}
+	return room, true
+}
+
+// queryInt parses an optional non-negative integer query parameter; missing means 0.
+func queryInt(c *gin.Context, key string) (int, error) {
+	v := c.Query(key)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s", key)
+	}
+	return n, nil
+}
+
 var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool { return true },
 }
 
 // ChatWebSocketHandler godoc
 // @Summary WebSocket endpoint for real-time chat
+// @Description Frames are JSON envelopes {"v":1,"type":...,"id":...,"payload":...}. Clients send message, message.edit, message.delete, dm, typing.start and typing.stop; the server sends message, message.edited, message.deleted, message.reaction, dm, typing.start, typing.stop, presence.join, presence.leave, presence.snapshot, notification, ack and error.
 // @Tags Chat
 // @Produce plain
 // @Param token query string true "JWT token"
+// @Param room query string false "Room name (default: general)"
+// @Param last_seen_id query int false "Replay messages after this id before live ones. If more than a maximum-size page was missed, an error frame asks to refetch the history instead"
 // @Success 101 {string} string "WebSocket Connection Established"
 // @Failure 401 {string} string "Unauthorized"
-// @Failure 403 {string} string "Forbidden"
+// @Failure 403 {string} string "Forbidden or banned from the room"
+// @Failure 404 {string} string "Room not found"
+// @Failure 500 {string} string "Could not join the room"
 // @Router /chat [get]
 func (h *ChatHandler) ChatWebSocketHandler(c *gin.Context) {
+	resp, ok := h.authenticate(c)
+	if !ok {
+		return
+	}
+	username := resp.Username
+
+	lastSeenID, err := queryInt(c, "last_seen_id")
+	if err != nil {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
 
+	room, ok := h.enterRoom(c, resp)
+	if !ok {
+		return
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+
+	client := newClient(h.hub, conn, h.cfg, room.ID, resp.UserId, username, resp.Role)
+	h.hub.Register(client)
+
+	if lastSeenID > 0 {
+		missed, err := h.usecase.MessagesSince(context.Background(), room.ID, lastSeenID)
+		switch {
+		case errors.Is(err, event.ErrTooFarBehind):
+			err = client.write(tooFarBehindFrame())
+		case err == nil:
+			err = client.replay(missed)
+		}
+		if err != nil {
+			h.logger.Error("Failed to replay missed chat messages", zap.String("username", username), zap.Error(err))
+			h.hub.Unregister(client)
+			conn.Close()
+			return
+		}
+	}
+
+	presence := domain.Presence{RoomID: room.ID, UserID: resp.UserId, Username: username}
+	_ = h.usecase.Connect(context.Background(), presence)
+	h.sendSnapshot(client)
+
+	go client.writePump()
+	go func() {
+		client.readPump(h.handleFrame)
+		if client.typing {
+			_ = h.usecase.SetTyping(context.Background(), client.roomID, client.username, false)
+		}
+		_ = h.usecase.Disconnect(context.Background(), presence)
+	}()
+}
+
+// authenticate validates the token query parameter. Browsers cannot set
+// headers on WebSocket and EventSource requests, so the token travels in the URL.
+func (h *ChatHandler) authenticate(c *gin.Context) (*authpb.ValidateTokenResponse, bool) {
 	token := c.Query("token")
 	if token == "" {
 		c.AbortWithStatus(http.StatusUnauthorized)
-		return
+		return nil, false
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
@@ -79,67 +336,189 @@ func (h *ChatHandler) ChatWebSocketHandler(c *gin.Context) {
 	if err != nil {
 		h.logger.Error("Failed:", zap.Error(err))
 		c.AbortWithStatus(http.StatusForbidden)
-		return
+		return nil, false
 	}
 	if !resp.Valid {
 		c.AbortWithStatus(http.StatusUnauthorized)
-		return
+		return nil, false
 	}
+	return resp, true
+}
 
-	username := resp.Username
+// enterRoom resolves the requested room, turns away banned users and records
+// the membership.
+func (h *ChatHandler) enterRoom(c *gin.Context, resp *authpb.ValidateTokenResponse) (domain.Room, bool) {
+	room, ok := h.resolveRoom(c)
+	if !ok {
+		return domain.Room{}, false
+	}
+	banned, err := h.usecase.IsBanned(c.Request.Context(), room.ID, resp.UserId)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return domain.Room{}, false
+	}
+	if banned {
+		c.AbortWithStatus(http.StatusForbidden)
+		return domain.Room{}, false
+	}
+	// The connection is not upgraded yet, so a failed join is answered with
+	// a plain error response instead of leaving a socket outside the room.
+	if err := h.usecase.JoinRoom(c.Request.Context(), room.ID, resp.UserId, resp.Username); err != nil {
+		h.logger.Error("Failed to join chat room", zap.Int("roomID", room.ID), zap.String("username", resp.Username), zap.Error(err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to join room"})
+		return domain.Room{}, false
+	}
+	return room, true
+}
 
-	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+// sendSnapshot queues the room's online users for a client that just connected.
+func (h *ChatHandler) sendSnapshot(c *Client) {
+	online, err := h.usecase.Online(context.Background(), c.roomID)
+	if err != nil {
+		return
+	}
+	data, err := newFrame(framePresenceSnapshot, "", snapshotPayload{RoomID: c.roomID, Online: online})
 	if err != nil {
 		return
 	}
-	h.clients[conn] = true
+	h.hub.SendTo(c, data)
+}
 
-	go func(conn *websocket.Conn, username string) {
-		defer func() {
-			conn.Close()
-			delete(h.clients, conn)
-		}()
-
-		for {
-			_, msg, err := conn.ReadMessage()
-			if err != nil {
-				break
-			}
-
-			var payload struct {
-				Content string `json:"content"`
-			}
-			if err := json.Unmarshal(msg, &payload); err != nil {
-				continue
-			}
-			if strings.TrimSpace(payload.Content) == "" {
-				continue
-			}
-
-			message := domain.ChatMessage{
-				Username:  username,
-				Content:   payload.Content,
-				Timestamp: time.Now(),
-			}
-			if err := h.usecase.SendMessage(context.Background(), message.Username, message.Content); err != nil {
-				continue
-			}
-
-			h.broadcast <- message
+// handleFrame executes a frame sent by the client. Delivery of the resulting
+// messages, including to this client, happens when the published event comes
+// back in Dispatch. Frames with an id are answered with an ack or an error;
+// command notices are always sent back in an ack.
+func (h *ChatHandler) handleFrame(c *Client, data []byte) {
+	var f Frame
+	if err := json.Unmarshal(data, &f); err != nil {
+		h.sendError(c, "", "malformed frame")
+		return
+	}
+	if f.Type == "" {
+		f = Frame{Type: frameMessage, Payload: data}
+	}
+	if f.V > protocolVersion {
+		h.sendError(c, f.ID, fmt.Sprintf("unsupported protocol version %d", f.V))
+		return
+	}
+
+	ctx := context.Background()
+	var ack ackPayload
+	switch f.Type {
+	case frameMessage:
+		var payload messagePayload
+		if err := json.Unmarshal(f.Payload, &payload); err != nil || strings.TrimSpace(payload.Content) == "" {
+			h.sendError(c, f.ID, "message needs \"content\"")
+			return
+		}
+		if err := h.usecase.CheckSend(ctx, c.roomID, c.userID, c.username); err != nil {
+			h.sendError(c, f.ID, errorText(err))
+			return
+		}
+		cmd := chatUsecase.Command{RoomID: c.roomID, UserID: c.userID, Username: c.username, Role: c.role}
+		res, err := h.usecase.Submit(ctx, cmd, payload.Content)
+		if res.Post == nil && res.Notice == "" {
+			h.sendError(c, f.ID, errorText(err))
+			return
+		}
+		if res.Post != nil {
+			ack.MessageID = res.Post.ID
+		}
+		ack.Notice = res.Notice
+		c.typing = false
+
+	case frameMessageEdit:
+		var payload editPayload
+		if err := json.Unmarshal(f.Payload, &payload); err != nil || payload.ID == 0 || strings.TrimSpace(payload.Content) == "" {
+			h.sendError(c, f.ID, "message.edit needs \"id\" and \"content\"")
+			return
+		}
+		msg, err := h.usecase.EditMessage(ctx, payload.ID, c.userID, c.username, payload.Content)
+		if msg.ID == 0 {
+			_, text := messageErrorStatus(err)
+			h.sendError(c, f.ID, text)
+			return
+		}
+		ack.MessageID = msg.ID
+
+	case frameMessageDelete:
+		var payload editPayload
+		if err := json.Unmarshal(f.Payload, &payload); err != nil || payload.ID == 0 {
+			h.sendError(c, f.ID, "message.delete needs \"id\"")
+			return
+		}
+		deletion, err := h.usecase.DeleteMessage(ctx, payload.ID, c.username, c.role)
+		if deletion.ID == 0 {
+			_, text := messageErrorStatus(err)
+			h.sendError(c, f.ID, text)
+			return
+		}
+		ack.MessageID = deletion.ID
+
+	case frameDirect:
+		var payload directPayload
+		if err := json.Unmarshal(f.Payload, &payload); err != nil || strings.TrimSpace(payload.Content) == "" {
+			h.sendError(c, f.ID, "direct message needs \"to\" and \"content\"")
+			return
+		}
+		msg, err := h.usecase.SendDirectMessage(ctx, c.userID, payload.To, payload.Content)
+		if msg.ID == 0 {
+			h.sendError(c, f.ID, errorText(err))
+			return
 		}
-	}(conn, username)
-}
-
-func (h *ChatHandler) handleMessages() {
-	for {
-		msg := <-h.broadcast
-		data, _ := json.Marshal(msg)
-		for client := range h.clients {
-			err := client.WriteMessage(websocket.TextMessage, data)
-			if err != nil {
-				client.Close()
-				delete(h.clients, client)
-			}
+		ack.MessageID = msg.ID
+
+	case frameTypingStart:
+		if c.typing && time.Since(c.typingSince) < typingRefresh {
+			break
+		}
+		c.typing, c.typingSince = true, time.Now()
+		_ = h.usecase.SetTyping(ctx, c.roomID, c.username, true)
+
+	case frameTypingStop:
+		if !c.typing {
+			break
 		}
+		c.typing = false
+		_ = h.usecase.SetTyping(ctx, c.roomID, c.username, false)
+
+	default:
+		h.sendError(c, f.ID, "unknown frame type "+f.Type)
+		return
+	}
+
+	if f.ID != "" || ack.Notice != "" {
+		h.reply(c, frameAck, f.ID, ack)
+	}
+}
+
+func (h *ChatHandler) sendError(c *Client, id, msg string) {
+	h.reply(c, frameError, id, errorPayload{Error: msg})
+}
+
+func (h *ChatHandler) reply(c *Client, frameType, id string, payload any) {
+	data, err := newFrame(frameType, id, payload)
+	if err != nil {
+		return
+	}
+	h.hub.SendTo(c, data)
+}
+
+// errorText turns a usecase error into a message that is safe to show to clients.
+func errorText(err error) string {
+	switch {
+	case errors.Is(err, domain.ErrMessageTooLong):
+		return "message too long"
+	case errors.Is(err, domain.ErrInvalidPeer):
+		return "unknown recipient"
+	case errors.Is(err, domain.ErrRoomNotFound):
+		return "room not found"
+	case errors.Is(err, domain.ErrBanned), errors.Is(err, domain.ErrMuted), errors.Is(err, domain.ErrSlowMode),
+		errors.Is(err, domain.ErrUnknownCommand), errors.Is(err, domain.ErrCommandUsage):
+		return err.Error()
+	case errors.Is(err, domain.ErrForbidden):
+		return "not allowed"
+	default:
+		return "failed to send message"
 	}
 }
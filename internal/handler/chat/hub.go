@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"context"
+	"expvar"
+
+	"go.uber.org/zap"
+)
+
+// liveConnections is published at /debug/vars as the number of registered chat sockets.
+var liveConnections = expvar.NewInt("chat_connections")
+
+// frame is an encoded message queued for a client. pos is set for new chat
+// messages, posts and comments so that frames already sent during a resume
+// replay can be skipped.
+type frame struct {
+	pos  position
+	data []byte
+}
+
+// outbound is a frame addressed to every client in a room, or, when userIDs
+// is set, to every connection of those users regardless of room. With kick
+// set, it is the last frame for those users' connections to roomID. With
+// forum set, it goes to every client following forum activity.
+type outbound struct {
+	roomID  int
+	userIDs []int32
+	kick    bool
+	forum   bool
+	frame   frame
+}
+
+// unicast is a frame for a single client, such as an error reply.
+type unicast struct {
+	client *Client
+	frame  frame
+}
+
+// Hub owns the set of connected clients. All membership changes and fan-out
+// happen on the Run goroutine, so no locking is needed around the maps.
+type Hub struct {
+	register   chan *Client
+	unregister chan *Client
+	broadcast  chan outbound
+	direct     chan unicast
+	rooms      map[int]map[*Client]struct{}
+	users      map[int32]map[*Client]struct{}
+	done       chan struct{}
+	logger     *zap.Logger
+}
+
+func NewHub(logger *zap.Logger) *Hub {
+	return &Hub{
+		register:   make(chan *Client),
+		unregister: make(chan *Client),
+		broadcast:  make(chan outbound, 256),
+		direct:     make(chan unicast, 64),
+		rooms:      make(map[int]map[*Client]struct{}),
+		users:      make(map[int32]map[*Client]struct{}),
+		done:       make(chan struct{}),
+		logger:     logger,
+	}
+}
+
+// Run processes hub events until ctx is cancelled, then disconnects every client.
+func (h *Hub) Run(ctx context.Context) {
+	defer close(h.done)
+
+	for {
+		select {
+		case c := <-h.register:
+			add(h.rooms, c.roomID, c)
+			add(h.users, c.userID, c)
+			liveConnections.Add(1)
+
+		case c := <-h.unregister:
+			h.remove(c)
+
+		case msg := <-h.broadcast:
+			if msg.kick {
+				for _, id := range msg.userIDs {
+					for c := range h.users[id] {
+						if c.roomID == msg.roomID {
+							h.deliver(c, msg.frame)
+							h.remove(c)
+						}
+					}
+				}
+				continue
+			}
+			if msg.forum {
+				for _, clients := range h.rooms {
+					for c := range clients {
+						if c.forum {
+							h.deliver(c, msg.frame)
+						}
+					}
+				}
+				continue
+			}
+			if msg.userIDs != nil {
+				for _, id := range msg.userIDs {
+					for c := range h.users[id] {
+						h.deliver(c, msg.frame)
+					}
+				}
+				continue
+			}
+			for c := range h.rooms[msg.roomID] {
+				h.deliver(c, msg.frame)
+			}
+
+		case msg := <-h.direct:
+			if _, ok := h.rooms[msg.client.roomID][msg.client]; ok {
+				h.deliver(msg.client, msg.frame)
+			}
+
+		case <-ctx.Done():
+			for _, clients := range h.rooms {
+				for c := range clients {
+					h.remove(c)
+				}
+			}
+			return
+		}
+	}
+}
+
+// deliver queues a frame without blocking; clients whose queue is full are dropped.
+func (h *Hub) deliver(c *Client, f frame) {
+	select {
+	case c.send <- f:
+	default:
+		h.logger.Warn("Dropping slow chat client", zap.String("username", c.username), zap.Int("roomID", c.roomID))
+		h.remove(c)
+	}
+}
+
+func add[K comparable](index map[K]map[*Client]struct{}, key K, c *Client) {
+	clients, ok := index[key]
+	if !ok {
+		clients = make(map[*Client]struct{})
+		index[key] = clients
+	}
+	clients[c] = struct{}{}
+}
+
+func drop[K comparable](index map[K]map[*Client]struct{}, key K, c *Client) {
+	delete(index[key], c)
+	if len(index[key]) == 0 {
+		delete(index, key)
+	}
+}
+
+// remove detaches a client and closes its send queue, which stops its writer.
+// It is a no-op for clients that were already removed.
+func (h *Hub) remove(c *Client) {
+	if _, ok := h.rooms[c.roomID][c]; !ok {
+		return
+	}
+	drop(h.rooms, c.roomID, c)
+	drop(h.users, c.userID, c)
+	close(c.send)
+	liveConnections.Add(-1)
+}
+
+// Register subscribes a client to its room. If the hub has stopped, the
+// client's send queue is closed straight away.
+func (h *Hub) Register(c *Client) {
+	select {
+	case h.register <- c:
+	case <-h.done:
+		close(c.send)
+	}
+}
+
+func (h *Hub) Unregister(c *Client) {
+	select {
+	case h.unregister <- c:
+	case <-h.done:
+	}
+}
+
+// Broadcast queues data for delivery to every client in the room. pos
+// identifies a new chat message carried by the frame, if any.
+func (h *Hub) Broadcast(roomID int, pos position, data []byte) {
+	select {
+	case h.broadcast <- outbound{roomID: roomID, frame: frame{pos: pos, data: data}}:
+	case <-h.done:
+	}
+}
+
+// Announce queues forum activity for every client that follows it.
+func (h *Hub) Announce(pos position, data []byte) {
+	select {
+	case h.broadcast <- outbound{forum: true, frame: frame{pos: pos, data: data}}:
+	case <-h.done:
+	}
+}
+
+// SendToUsers queues data for every connection of the given users.
+func (h *Hub) SendToUsers(userIDs []int32, data []byte) {
+	select {
+	case h.broadcast <- outbound{userIDs: userIDs, frame: frame{data: data}}:
+	case <-h.done:
+	}
+}
+
+// Kick sends data to the users' connections to a room and then closes them.
+func (h *Hub) Kick(roomID int, userIDs []int32, data []byte) {
+	select {
+	case h.broadcast <- outbound{roomID: roomID, userIDs: userIDs, kick: true, frame: frame{data: data}}:
+	case <-h.done:
+	}
+}
+
+// SendTo queues data for a single client if it is still connected.
+func (h *Hub) SendTo(c *Client, data []byte) {
+	select {
+	case h.direct <- unicast{client: c, frame: frame{data: data}}:
+	case <-h.done:
+	}
+}
@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func startHub(t *testing.T) *Hub {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	h := NewHub(zap.NewNop())
+	go h.Run(ctx)
+	t.Cleanup(func() {
+		cancel()
+		<-h.done
+	})
+	return h
+}
+
+func testClient(h *Hub, roomID int, userID int32, buffer int) *Client {
+	return &Client{hub: h, send: make(chan frame, buffer), roomID: roomID, userID: userID}
+}
+
+// receive waits for the next frame queued for c. ok is false once the hub
+// has closed the queue.
+func receive(t *testing.T, c *Client) (data string, ok bool) {
+	t.Helper()
+	select {
+	case f, ok := <-c.send:
+		return string(f.data), ok
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a frame")
+		return "", false
+	}
+}
+
+// expectNothing fails if a frame is queued for c within a short wait.
+func expectNothing(t *testing.T, c *Client) {
+	t.Helper()
+	select {
+	case f, ok := <-c.send:
+		t.Fatalf("unexpected frame %q (open: %v)", f.data, ok)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestHubBroadcastReachesRoomOnly(t *testing.T) {
+	h := startHub(t)
+	a := testClient(h, 1, 10, 4)
+	b := testClient(h, 1, 11, 4)
+	other := testClient(h, 2, 12, 4)
+	h.Register(a)
+	h.Register(b)
+	h.Register(other)
+
+	h.Broadcast(1, position{}, []byte("hello"))
+
+	for _, c := range []*Client{a, b} {
+		if data, ok := receive(t, c); !ok || data != "hello" {
+			t.Fatalf("got %q, %v; want hello", data, ok)
+		}
+	}
+	expectNothing(t, other)
+}
+
+func TestHubUnregisterClosesQueue(t *testing.T) {
+	h := startHub(t)
+	before := liveConnections.Value()
+	c := testClient(h, 1, 10, 4)
+	h.Register(c)
+	if got := liveConnections.Value(); got != before+1 {
+		t.Fatalf("live connections = %d, want %d", got, before+1)
+	}
+
+	h.Unregister(c)
+	if _, ok := receive(t, c); ok {
+		t.Fatal("queue still open after Unregister")
+	}
+	if got := liveConnections.Value(); got != before {
+		t.Fatalf("live connections = %d, want %d", got, before)
+	}
+
+	// A second unregister, as readPump does after a kick, must not panic.
+	h.Unregister(c)
+	h.Broadcast(1, position{}, []byte("late"))
+}
+
+func TestHubDropsSlowClient(t *testing.T) {
+	h := startHub(t)
+	slow := testClient(h, 1, 10, 1)
+	fast := testClient(h, 1, 11, 4)
+	h.Register(slow)
+	h.Register(fast)
+
+	h.Broadcast(1, position{}, []byte("one"))
+	h.Broadcast(1, position{}, []byte("two"))
+	h.Broadcast(1, position{}, []byte("three"))
+
+	// Each broadcast reaches all clients in one step of the hub, so once the
+	// fast client has the last frame the slow one has been dealt with too.
+	for _, want := range []string{"one", "two", "three"} {
+		if data, ok := receive(t, fast); !ok || data != want {
+			t.Fatalf("got %q, %v; want %s", data, ok, want)
+		}
+	}
+	if data, ok := receive(t, slow); !ok || data != "one" {
+		t.Fatalf("got %q, %v; want one", data, ok)
+	}
+	if _, ok := receive(t, slow); ok {
+		t.Fatal("slow client was not dropped")
+	}
+}
+
+func TestHubSendToUsersAndKick(t *testing.T) {
+	h := startHub(t)
+	inRoom := testClient(h, 1, 10, 4)
+	elsewhere := testClient(h, 2, 10, 4)
+	bystander := testClient(h, 1, 11, 4)
+	h.Register(inRoom)
+	h.Register(elsewhere)
+	h.Register(bystander)
+
+	h.SendToUsers([]int32{10}, []byte("dm"))
+	for _, c := range []*Client{inRoom, elsewhere} {
+		if data, ok := receive(t, c); !ok || data != "dm" {
+			t.Fatalf("got %q, %v; want dm", data, ok)
+		}
+	}
+	expectNothing(t, bystander)
+
+	h.Kick(1, []int32{10}, []byte("banned"))
+	if data, ok := receive(t, inRoom); !ok || data != "banned" {
+		t.Fatalf("got %q, %v; want banned", data, ok)
+	}
+	if _, ok := receive(t, inRoom); ok {
+		t.Fatal("kicked client still registered")
+	}
+	expectNothing(t, elsewhere)
+	expectNothing(t, bystander)
+}
+
+func TestHubStopClosesClients(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	h := NewHub(zap.NewNop())
+	go h.Run(ctx)
+	c := testClient(h, 1, 10, 4)
+	h.Register(c)
+
+	cancel()
+	<-h.done
+	if _, ok := receive(t, c); ok {
+		t.Fatal("queue still open after the hub stopped")
+	}
+
+	late := testClient(h, 1, 11, 4)
+	h.Register(late)
+	if _, ok := receive(t, late); ok {
+		t.Fatal("queue of a client registered after stop is open")
+	}
+	h.Broadcast(1, position{}, []byte("ignored"))
+}
+
+// TestHubConcurrentUse exercises the hub from many goroutines at once; run
+// it with -race.
+func TestHubConcurrentUse(t *testing.T) {
+	h := startHub(t)
+	var wg sync.WaitGroup
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c := testClient(h, i%3, int32(i), 8)
+			h.Register(c)
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for range c.send {
+				}
+			}()
+			for j := 0; j < 50; j++ {
+				h.Broadcast(i%3, position{}, []byte("msg"))
+				h.SendTo(c, []byte("ack"))
+			}
+			h.Unregister(c)
+			<-done
+		}(i)
+	}
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				h.SendToUsers([]int32{1, 2, 3}, []byte("dm"))
+				h.Announce(position{}, []byte("post"))
+			}
+		}()
+	}
+	wg.Wait()
+}
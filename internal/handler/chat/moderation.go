@@ -0,0 +1,265 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	domain "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/chat"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/user"
+	"github.com/gin-gonic/gin"
+)
+
+type MuteInput struct {
+	Username string `json:"username" binding:"required"`
+	Room     string `json:"room"`                        // empty mutes the user in every room
+	Duration string `json:"duration" binding:"required"` // Go duration, e.g. "10m" or "24h"
+	Reason   string `json:"reason"`
+}
+
+type BanInput struct {
+	Username string `json:"username" binding:"required"`
+	Room     string `json:"room" binding:"required"`
+	Reason   string `json:"reason"`
+}
+
+type SlowModeInput struct {
+	Room    string `json:"room" binding:"required"`
+	Seconds int    `json:"seconds" binding:"min=0,max=3600"`
+}
+
+type RetentionInput struct {
+	Room        string `json:"room" binding:"required"`
+	MaxAge      string `json:"max_age"` // Go duration up to 87600h (10 years), e.g. "720h"; "0s" keeps messages forever
+	MaxMessages *int   `json:"max_messages" binding:"omitempty,min=0,max=2147483647"`
+	Archive     *bool  `json:"archive"`
+}
+
+// ModerationHandler godoc
+// @Summary List active chat mutes and bans
+// @Tags Chat moderation
+// @Produce json
+// @Param room query string false "Only this room and global mutes"
+// @Success 200 {object} domain.Moderation
+// @Failure 401,403,404,500 {object} response.ErrorResponse
+// @Router /chat/admin/moderation [get]
+func (h *ChatHandler) ModerationHandler(c *gin.Context) {
+	roomID, ok := h.optionalRoom(c, c.Query("room"))
+	if !ok {
+		return
+	}
+
+	mod, err := h.usecase.GetModeration(c.Request.Context(), roomID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get moderation"})
+		return
+	}
+	c.JSON(http.StatusOK, mod)
+}
+
+// MuteHandler godoc
+// @Summary Mute a chat user for a duration
+// @Tags Chat moderation
+// @Accept json
+// @Produce json
+// @Param mute body MuteInput true "User, optional room, duration and reason"
+// @Success 201 {object} domain.Mute
+// @Failure 400,401,403,404,500 {object} response.ErrorResponse
+// @Router /chat/admin/mutes [post]
+func (h *ChatHandler) MuteHandler(c *gin.Context) {
+	var input MuteInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	d, err := time.ParseDuration(input.Duration)
+	if err != nil || d <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid duration"})
+		return
+	}
+	roomID, ok := h.optionalRoom(c, input.Room)
+	if !ok {
+		return
+	}
+
+	m, err := h.usecase.Mute(c.Request.Context(), roomID, input.Username, d, input.Reason, c.GetString("username"))
+	if m.ID == 0 {
+		moderationError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, m)
+}
+
+// UnmuteHandler godoc
+// @Summary Lift a chat user's mutes
+// @Tags Chat moderation
+// @Produce json
+// @Param username query string true "Username"
+// @Param room query string false "Room; empty lifts the mutes covering every room"
+// @Success 200 {object} response.MessageResponse
+// @Failure 401,403,404,500 {object} response.ErrorResponse
+// @Router /chat/admin/mutes [delete]
+func (h *ChatHandler) UnmuteHandler(c *gin.Context) {
+	roomID, ok := h.optionalRoom(c, c.Query("room"))
+	if !ok {
+		return
+	}
+
+	if _, err := h.usecase.Unmute(c.Request.Context(), roomID, c.Query("username")); err != nil {
+		moderationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "user unmuted"})
+}
+
+// BanHandler godoc
+// @Summary Ban a user from a chat room
+// @Description Open sockets of the user in the room receive a moderation.banned frame and are closed.
+// @Tags Chat moderation
+// @Accept json
+// @Produce json
+// @Param ban body BanInput true "User, room and reason"
+// @Success 201 {object} domain.Ban
+// @Failure 400,401,403,404,500 {object} response.ErrorResponse
+// @Router /chat/admin/bans [post]
+func (h *ChatHandler) BanHandler(c *gin.Context) {
+	var input BanInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	roomID, ok := h.optionalRoom(c, input.Room)
+	if !ok {
+		return
+	}
+
+	b, err := h.usecase.Ban(c.Request.Context(), *roomID, input.Username, input.Reason, c.GetString("username"))
+	if b.UserID == 0 {
+		moderationError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, b)
+}
+
+// UnbanHandler godoc
+// @Summary Lift a chat room ban
+// @Tags Chat moderation
+// @Produce json
+// @Param username query string true "Username"
+// @Param room query string true "Room name"
+// @Success 200 {object} response.MessageResponse
+// @Failure 400,401,403,404,500 {object} response.ErrorResponse
+// @Router /chat/admin/bans [delete]
+func (h *ChatHandler) UnbanHandler(c *gin.Context) {
+	if c.Query("room") == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "room is required"})
+		return
+	}
+	roomID, ok := h.optionalRoom(c, c.Query("room"))
+	if !ok {
+		return
+	}
+
+	if _, err := h.usecase.Unban(c.Request.Context(), *roomID, c.Query("username")); err != nil {
+		moderationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "user unbanned"})
+}
+
+// SlowModeHandler godoc
+// @Summary Set slow mode for a chat room
+// @Description Users must wait the given number of seconds between messages; 0 turns slow mode off. Connected clients receive a room.updated frame.
+// @Tags Chat moderation
+// @Accept json
+// @Produce json
+// @Param slowmode body SlowModeInput true "Room and seconds"
+// @Success 200 {object} domain.Room
+// @Failure 400,401,403,404,500 {object} response.ErrorResponse
+// @Router /chat/admin/slowmode [put]
+func (h *ChatHandler) SlowModeHandler(c *gin.Context) {
+	var input SlowModeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	roomID, ok := h.optionalRoom(c, input.Room)
+	if !ok {
+		return
+	}
+
+	room, err := h.usecase.SetSlowMode(c.Request.Context(), *roomID, input.Seconds, c.GetString("username"))
+	if room.ID == 0 {
+		moderationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, room)
+}
+
+// RetentionHandler godoc
+// @Summary Set a chat room's retention policy
+// @Description Omitted fields fall back to the global CHAT_RETENTION_* settings; 0 disables a limit for the room.
+// @Tags Chat moderation
+// @Accept json
+// @Produce json
+// @Param retention body RetentionInput true "Room and retention overrides"
+// @Success 200 {object} domain.Room
+// @Failure 400,401,403,404,500 {object} response.ErrorResponse
+// @Router /chat/admin/retention [put]
+func (h *ChatHandler) RetentionHandler(c *gin.Context) {
+	var input RetentionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	ret := domain.Retention{MaxMessages: input.MaxMessages, Archive: input.Archive}
+	if input.MaxAge != "" {
+		d, err := time.ParseDuration(input.MaxAge)
+		if err != nil || d < 0 || d > domain.MaxRetentionAge {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid max_age, use 0s to 87600h"})
+			return
+		}
+		seconds := int(d.Seconds())
+		ret.MaxAgeSeconds = &seconds
+	}
+	roomID, ok := h.optionalRoom(c, input.Room)
+	if !ok {
+		return
+	}
+
+	room, err := h.usecase.SetRetention(c.Request.Context(), *roomID, ret, c.GetString("username"))
+	if room.ID == 0 {
+		moderationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, room)
+}
+
+// optionalRoom resolves a room name to its id; an empty name gives nil.
+// It writes an error response if the room cannot be found.
+func (h *ChatHandler) optionalRoom(c *gin.Context, name string) (*int, bool) {
+	if name == "" {
+		return nil, true
+	}
+	room, err := h.usecase.ResolveRoom(c.Request.Context(), name)
+	switch {
+	case errors.Is(err, domain.ErrRoomNotFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "room not found"})
+		return nil, false
+	case err != nil:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to get room"})
+		return nil, false
+	}
+	return &room.ID, true
+}
+
+func moderationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, user.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	case errors.Is(err, domain.ErrRoomNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "room not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update moderation"})
+	}
+}
@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	domain "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/chat"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/event"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/middleware"
+	chatUsecase "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/usecase/chat"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type SendMessageInput struct {
+	Room    string `json:"room"` // default: general
+	Content string `json:"content" binding:"required"`
+}
+
+// EventsHandler godoc
+// @Summary Server-Sent Events stream for chat and forum activity
+// @Description Fallback for clients that cannot open the /chat WebSocket. Every event's data is a frame envelope as on the WebSocket; besides the room's chat frames it carries post.created and comment.created. Event ids have the form "<message>-<post>-<comment>"; reconnecting with Last-Event-ID (or last_event_id) replays what was missed. A stream missed by more than a maximum-size page is not replayed; an error frame asks to refetch it instead. Send messages with POST /chat/messages.
+// @Tags Chat
+// @Produce text/event-stream
+// @Param token query string true "JWT token"
+// @Param room query string false "Room name (default: general)"
+// @Param last_event_id query string false "Resume position when the Last-Event-ID header cannot be set"
+// @Success 200 {string} string "Event stream"
+// @Failure 400 {string} string "Invalid Last-Event-ID"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden or banned from the room"
+// @Failure 404 {string} string "Room not found"
+// @Failure 500 {string} string "Could not join the room"
+// @Router /chat/events [get]
+func (h *ChatHandler) EventsHandler(c *gin.Context) {
+	resp, ok := h.authenticate(c)
+	if !ok {
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	var resume position
+	if lastEventID != "" {
+		var err error
+		if resume, err = parsePosition(lastEventID); err != nil {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+	}
+
+	room, ok := h.enterRoom(c, resp)
+	if !ok {
+		return
+	}
+
+	client := newClient(h.hub, nil, h.cfg, room.ID, resp.UserId, resp.Username, resp.Role)
+	client.forum = true
+	h.hub.Register(client)
+	defer h.hub.Unregister(client)
+
+	ctx := c.Request.Context()
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	pos, err := h.startPosition(ctx, c.Writer, client, resume)
+	if err != nil {
+		h.logger.Error("Failed to replay missed events", zap.String("username", resp.Username), zap.Error(err))
+		return
+	}
+	fmt.Fprintf(c.Writer, "retry: 3000\nid: %s\n\n", pos)
+	c.Writer.Flush()
+
+	presence := domain.Presence{RoomID: room.ID, UserID: resp.UserId, Username: resp.Username}
+	_ = h.usecase.Connect(context.Background(), presence)
+	defer h.usecase.Disconnect(context.Background(), presence)
+	h.sendSnapshot(client)
+
+	ticker := time.NewTicker(h.cfg.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case f, ok := <-client.send:
+			if !ok {
+				return
+			}
+			if _, dup := client.replayed[f.pos]; dup {
+				continue
+			}
+			pos.advance(f.pos)
+			if err := writeEvent(c.Writer, pos, f); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// startPosition replays what a resuming client missed, records it in
+// client.replayed and returns the position after it. New clients start at the
+// latest message, post and comment, and so do clients too far behind.
+func (h *ChatHandler) startPosition(ctx context.Context, w gin.ResponseWriter, client *Client, resume position) (position, error) {
+	roomID := client.roomID
+	if resume.isZero() {
+		var pos position
+		var err error
+		if pos.Message, err = h.usecase.LatestMessageID(ctx, roomID); err != nil {
+			return pos, err
+		}
+		if pos.Post, err = h.posts.LatestPostID(ctx); err != nil {
+			return pos, err
+		}
+		pos.Comment, err = h.comments.LatestCommentID(ctx)
+		return pos, err
+	}
+
+	pos := resume
+	send := func(frameType string, id int, item position, payload any) error {
+		data, err := newFrame(frameType, strconv.Itoa(id), payload)
+		if err != nil {
+			return err
+		}
+		pos.advance(item)
+		client.replayed[item] = struct{}{}
+		return writeEvent(w, pos, frame{pos: item, data: data})
+	}
+
+	messages, err := h.usecase.MessagesSince(ctx, roomID, resume.Message)
+	if errors.Is(err, event.ErrTooFarBehind) {
+		if pos.Message, err = h.usecase.LatestMessageID(ctx, roomID); err != nil {
+			return pos, err
+		}
+		err = writeEvent(w, position{}, frame{data: tooFarBehindFrame()})
+	}
+	if err != nil {
+		return pos, err
+	}
+	for _, msg := range messages {
+		if err := send(frameMessage, msg.ID, position{Message: msg.ID}, msg); err != nil {
+			return pos, err
+		}
+	}
+
+	posts, err := h.posts.PostsSince(ctx, resume.Post)
+	if errors.Is(err, event.ErrTooFarBehind) {
+		if pos.Post, err = h.posts.LatestPostID(ctx); err != nil {
+			return pos, err
+		}
+		err = writeEvent(w, position{}, frame{data: tooFarBehindFrame()})
+	}
+	if err != nil {
+		return pos, err
+	}
+	for _, p := range posts {
+		if err := send(framePostCreated, p.ID, position{Post: p.ID}, p); err != nil {
+			return pos, err
+		}
+	}
+
+	comments, err := h.comments.CommentsSince(ctx, resume.Comment)
+	if errors.Is(err, event.ErrTooFarBehind) {
+		if pos.Comment, err = h.comments.LatestCommentID(ctx); err != nil {
+			return pos, err
+		}
+		err = writeEvent(w, position{}, frame{data: tooFarBehindFrame()})
+	}
+	if err != nil {
+		return pos, err
+	}
+	for _, cm := range comments {
+		if err := send(frameCommentCreated, cm.ID, position{Comment: cm.ID}, cm); err != nil {
+			return pos, err
+		}
+	}
+	return pos, nil
+}
+
+// writeEvent writes one frame as an SSE event. Frames about resumable items
+// carry the client's position as the event id.
+func writeEvent(w gin.ResponseWriter, pos position, f frame) error {
+	var err error
+	if f.pos.isZero() {
+		_, err = fmt.Fprintf(w, "data: %s\n\n", f.data)
+	} else {
+		_, err = fmt.Fprintf(w, "id: %s\ndata: %s\n\n", pos, f.data)
+	}
+	if err != nil {
+		return err
+	}
+	w.Flush()
+	return nil
+}
+
+// SendChatMessageHandler godoc
+// @Summary Send a chat message
+// @Description Posts to a room like a WebSocket message frame, including slash commands. Connected clients receive it as a message frame.
+// @Tags Chat
+// @Accept json
+// @Produce json
+// @Param message body SendMessageInput true "Room and content"
+// @Success 201 {object} domain.ChatMessage
+// @Success 200 {object} response.MessageResponse "Command notice"
+// @Failure 400,401,403,404,429,500 {object} response.ErrorResponse
+// @Router /chat/messages [post]
+func (h *ChatHandler) SendChatMessageHandler(c *gin.Context) {
+	var input SendMessageInput
+	if err := c.ShouldBindJSON(&input); err != nil || strings.TrimSpace(input.Content) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	name := input.Room
+	if name == "" {
+		name = domain.DefaultRoom
+	}
+	roomID, ok := h.optionalRoom(c, name)
+	if !ok {
+		return
+	}
+
+	userID := middleware.CurrentUserID(c)
+	username := c.GetString("username")
+	if err := h.usecase.CheckSend(ctx, *roomID, userID, username); err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, domain.ErrBanned), errors.Is(err, domain.ErrMuted):
+			status = http.StatusForbidden
+		case errors.Is(err, domain.ErrSlowMode):
+			status = http.StatusTooManyRequests
+		case errors.Is(err, domain.ErrRoomNotFound):
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": errorText(err)})
+		return
+	}
+
+	cmd := chatUsecase.Command{RoomID: *roomID, UserID: userID, Username: username, Role: c.GetString("role")}
+	res, err := h.usecase.Submit(ctx, cmd, input.Content)
+	switch {
+	case res.Post != nil:
+		c.JSON(http.StatusCreated, res.Post)
+	case res.Notice != "":
+		c.JSON(http.StatusOK, gin.H{"message": res.Notice})
+	case errors.Is(err, domain.ErrMessageTooLong), errors.Is(err, domain.ErrUnknownCommand), errors.Is(err, domain.ErrCommandUsage):
+		c.JSON(http.StatusBadRequest, gin.H{"error": errorText(err)})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": errorText(err)})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message"})
+	}
+}
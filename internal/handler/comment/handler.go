@@ -2,10 +2,14 @@ package comment
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/AdminGo/proto/authpb"
+	models "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/comment"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/trash"
+	response "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/handler"
 	usecase "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/usecase/comment"
 	"go.uber.org/zap"
 
@@ -19,8 +23,9 @@ type Handler struct {
 }
 
 type CreateCommentInput struct {
-	PostID  int    `json:"post_id"`
-	Content string `json:"content"`
+	PostID   int    `json:"post_id"`
+	ParentID *int   `json:"parent_id"`
+	Content  string `json:"content"`
 }
 
 func NewCommentHandler(r *gin.RouterGroup, uc *usecase.Usecase, authClient authpb.AuthServiceClient, logger *zap.Logger) {
@@ -31,8 +36,10 @@ func NewCommentHandler(r *gin.RouterGroup, uc *usecase.Usecase, authClient authp
 	}
 
 	r.GET("/comments", h.GetComments)
+	r.GET("/comments/thread", h.GetThread)
 	r.POST("/comments/create", h.CreateComment)
 	r.DELETE("/comments/delete", h.DeleteComment)
+	r.POST("/comments/restore", h.RestoreComment)
 }
 
 // GetComments godoc
@@ -40,6 +47,8 @@ func NewCommentHandler(r *gin.RouterGroup, uc *usecase.Usecase, authClient authp
 // @Tags Comments
 // @Produce json
 // @Param post_id query int true "Post ID"
+// @Param cursor query string false "Cursor from next_cursor of the previous page"
+// @Param limit query int false "Page size"
 // @Success 200 {object} response.DataCommentsResponse
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
@@ -52,14 +61,62 @@ func (h *Handler) GetComments(c *gin.Context) {
 		return
 	}
 
-	comments, err := h.usecase.GetCommentsByPost(c.Request.Context(), postID)
+	page, ok := response.Page(c, "invalid cursor or limit")
+	if !ok {
+		return
+	}
+
+	comments, next, err := h.usecase.GetCommentsByPost(c.Request.Context(), postID, page)
 	if err != nil {
 		h.logger.Error("failed to fetch comments", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not fetch comments"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": comments})
+	response.List(c, comments, next)
+}
+
+// GetThread godoc
+// @Summary Get the comment tree of a post
+// @Description Pages over top-level comments, oldest first; each comes with all of its replies. Returns nested replies, or a flat list ordered by thread path when format=flat.
+// @Tags Comments
+// @Produce json
+// @Param post_id query int true "Post ID"
+// @Param format query string false "tree (default) or flat"
+// @Param cursor query string false "Cursor from next_cursor of the previous page"
+// @Param limit query int false "Number of top-level comments"
+// @Success 200 {object} response.DataCommentsResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /comments/thread [get]
+func (h *Handler) GetThread(c *gin.Context) {
+	postID, err := strconv.Atoi(c.Query("post_id"))
+	if err != nil {
+		h.logger.Error("invalid post_id", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post_id"})
+		return
+	}
+	page, ok := response.Page(c, "invalid cursor or limit")
+	if !ok {
+		return
+	}
+
+	if c.Query("format") == "flat" {
+		comments, next, err := h.usecase.GetThreadFlat(c.Request.Context(), postID, page)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not fetch comments"})
+			return
+		}
+		response.List(c, comments, next)
+		return
+	}
+
+	tree, next, err := h.usecase.GetThread(c.Request.Context(), postID, page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not fetch comments"})
+		return
+	}
+	response.List(c, tree, next)
 }
 
 // CreateComment godoc
@@ -67,9 +124,9 @@ func (h *Handler) GetComments(c *gin.Context) {
 // @Tags Comments
 // @Accept json
 // @Produce json
-// @Param comment body comment.CreateCommentInput true "Comment content"
+// @Param comment body comment.CreateCommentInput true "Comment content; parent_id makes it a reply"
 // @Success 200 {object} response.MessageResponse
-// @Failure 400,401,500 {object} response.ErrorResponse
+// @Failure 400,401,404,409,500 {object} response.ErrorResponse
 // @Router /comments/create [post]
 func (h *Handler) CreateComment(c *gin.Context) {
 	var input CreateCommentInput
@@ -91,8 +148,24 @@ func (h *Handler) CreateComment(c *gin.Context) {
 		return
 	}
 
-	err = h.usecase.CreateComment(c.Request.Context(), input.PostID, resp.Username, input.Content)
-	if err != nil {
+	err = h.usecase.CreateComment(c.Request.Context(), input.PostID, input.ParentID, resp.Username, input.Content)
+	switch {
+	case errors.Is(err, models.ErrParentNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "parent comment not found"})
+		return
+	case errors.Is(err, models.ErrMaxDepth):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "maximum reply depth reached"})
+		return
+	case errors.Is(err, models.ErrPostNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "post not found"})
+		return
+	case errors.Is(err, models.ErrPostLocked):
+		c.JSON(http.StatusConflict, gin.H{"error": "post is locked, new comments are not accepted"})
+		return
+	case errors.Is(err, models.ErrPostArchived):
+		c.JSON(http.StatusConflict, gin.H{"error": "post is archived and read-only"})
+		return
+	case err != nil:
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create comment"})
 		return
 	}
@@ -101,12 +174,13 @@ func (h *Handler) CreateComment(c *gin.Context) {
 }
 
 // DeleteComment godoc
-// @Summary Delete a comment by ID (admin only)
+// @Summary Move a comment to the trash (admin only)
+// @Description A comment with replies stays in the thread as a placeholder.
 // @Tags Comments
 // @Produce json
 // @Param comment_id query int true "Comment ID"
 // @Success 200 {object} response.MessageResponse
-// @Failure 400,401,403,500 {object} response.ErrorResponse
+// @Failure 400,401,403,404,500 {object} response.ErrorResponse
 // @Router /comments/delete [delete]
 func (h *Handler) DeleteComment(c *gin.Context) {
 	commentID, err := strconv.Atoi(c.Query("comment_id"))
@@ -116,20 +190,17 @@ func (h *Handler) DeleteComment(c *gin.Context) {
 		return
 	}
 
-	token := extractBearerToken(c.GetHeader("Authorization"))
-	if token == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+	admin, ok := h.requireAdmin(c)
+	if !ok {
 		return
 	}
 
-	resp, err := h.authService.ValidateToken(context.Background(), &authpb.ValidateTokenRequest{Token: token})
-	if err != nil || !resp.Valid || resp.Role != "ADMIN" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "admin only"})
+	err = h.usecase.DeleteComment(c.Request.Context(), commentID, admin)
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "comment not found"})
 		return
-	}
-
-	err = h.usecase.DeleteComment(c.Request.Context(), commentID)
-	if err != nil {
+	case err != nil:
 		h.logger.Error("invalid delete", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not delete comment"})
 		return
@@ -138,6 +209,59 @@ func (h *Handler) DeleteComment(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "comment deleted"})
 }
 
+// RestoreComment godoc
+// @Summary Restore a comment from the trash (admin only)
+// @Description Deleted comments it replies to come back as placeholders. Comments of a deleted post come back when the post is restored.
+// @Tags Comments
+// @Produce json
+// @Param comment_id query int true "Comment ID"
+// @Success 200 {object} response.MessageResponse
+// @Failure 400,401,403,404,409,500 {object} response.ErrorResponse
+// @Router /comments/restore [post]
+func (h *Handler) RestoreComment(c *gin.Context) {
+	commentID, err := strconv.Atoi(c.Query("comment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment_id"})
+		return
+	}
+
+	if _, ok := h.requireAdmin(c); !ok {
+		return
+	}
+
+	err = h.usecase.RestoreComment(c.Request.Context(), commentID)
+	switch {
+	case errors.Is(err, trash.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "comment is not in the trash"})
+		return
+	case errors.Is(err, trash.ErrParentDeleted):
+		c.JSON(http.StatusConflict, gin.H{"error": "post of the comment is in the trash"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not restore comment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "comment restored"})
+}
+
+// requireAdmin validates the bearer token and returns the username of the
+// ADMIN it belongs to. Otherwise it writes the error response and reports false.
+func (h *Handler) requireAdmin(c *gin.Context) (string, bool) {
+	token := extractBearerToken(c.GetHeader("Authorization"))
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return "", false
+	}
+
+	resp, err := h.authService.ValidateToken(context.Background(), &authpb.ValidateTokenRequest{Token: token})
+	if err != nil || !resp.Valid || resp.Role != "ADMIN" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "admin only"})
+		return "", false
+	}
+	return resp.Username, true
+}
+
 func extractBearerToken(header string) string {
 	const prefix = "Bearer "
 	if len(header) > len(prefix) && header[:len(prefix)] == prefix {
@@ -0,0 +1,210 @@
+package notification
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/notification"
+	response "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/handler"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/middleware"
+	usecase "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/usecase/notification"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	usecase *usecase.UseCase
+	logger  *zap.Logger
+}
+
+type MarkReadInput struct {
+	IDs []int `json:"ids"` // empty marks every notification read
+}
+
+type SubscriptionInput struct {
+	TopicID *int `json:"topic_id"`
+	PostID  *int `json:"post_id"`
+}
+
+func NewNotificationHandler(rg *gin.RouterGroup, uc *usecase.UseCase, authMiddleware gin.HandlerFunc, logger *zap.Logger) {
+	h := &Handler{usecase: uc, logger: logger}
+
+	rg.GET("/notifications", authMiddleware, h.GetNotifications)
+	rg.GET("/notifications/unread", authMiddleware, h.CountUnread)
+	rg.POST("/notifications/read", authMiddleware, h.MarkRead)
+	rg.GET("/mentions", authMiddleware, h.GetMentions)
+
+	rg.GET("/subscriptions", authMiddleware, h.GetSubscriptions)
+	rg.POST("/subscriptions", authMiddleware, h.Subscribe)
+	rg.DELETE("/subscriptions", authMiddleware, h.Unsubscribe)
+}
+
+// GetNotifications godoc
+// @Summary List notifications of the current user
+// @Description Newest first. New notifications are also pushed over the /chat WebSocket and /chat/events as "notification" frames.
+// @Tags Notifications
+// @Produce json
+// @Param unread query bool false "Only unread notifications"
+// @Param cursor query string false "Cursor from next_cursor of the previous page"
+// @Param limit query int false "Page size"
+// @Success 200 {object} response.DataNotificationsResponse
+// @Failure 400,401,500 {object} response.ErrorResponse
+// @Router /notifications [get]
+func (h *Handler) GetNotifications(c *gin.Context) {
+	page, ok := response.Page(c, "invalid cursor or limit")
+	if !ok {
+		return
+	}
+
+	list, next, err := h.usecase.GetByUser(c.Request.Context(), middleware.CurrentUserID(c), c.Query("unread") == "true", page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not fetch notifications"})
+		return
+	}
+	response.List(c, list, next)
+}
+
+// CountUnread godoc
+// @Summary Count unread notifications of the current user
+// @Tags Notifications
+// @Produce json
+// @Success 200 {object} response.UnreadResponse
+// @Failure 401,500 {object} response.ErrorResponse
+// @Router /notifications/unread [get]
+func (h *Handler) CountUnread(c *gin.Context) {
+	n, err := h.usecase.CountUnread(c.Request.Context(), middleware.CurrentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not count notifications"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"unread": n})
+}
+
+// MarkRead godoc
+// @Summary Mark notifications as read
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param input body notification.MarkReadInput false "Notification ids; omit to mark all read"
+// @Success 200 {object} response.MessageResponse
+// @Failure 400,401,500 {object} response.ErrorResponse
+// @Router /notifications/read [post]
+func (h *Handler) MarkRead(c *gin.Context) {
+	var input MarkReadInput
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+			return
+		}
+	}
+
+	if _, err := h.usecase.MarkRead(c.Request.Context(), middleware.CurrentUserID(c), input.IDs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not mark notifications read"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "notifications marked read"})
+}
+
+// GetMentions godoc
+// @Summary List the posts, comments and chat messages that mention the current user
+// @Description Newest first. Each entry points at its source by post_id and comment_id, or by room_id and message_id.
+// @Tags Notifications
+// @Produce json
+// @Param cursor query string false "Cursor from next_cursor of the previous page"
+// @Param limit query int false "Page size"
+// @Success 200 {object} response.DataMentionsResponse
+// @Failure 400,401,500 {object} response.ErrorResponse
+// @Router /mentions [get]
+func (h *Handler) GetMentions(c *gin.Context) {
+	page, ok := response.Page(c, "invalid cursor or limit")
+	if !ok {
+		return
+	}
+
+	mentions, next, err := h.usecase.GetMentions(c.Request.Context(), middleware.CurrentUserID(c), page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not fetch mentions"})
+		return
+	}
+	response.List(c, mentions, next)
+}
+
+// GetSubscriptions godoc
+// @Summary List the topics and posts the current user follows
+// @Tags Notifications
+// @Produce json
+// @Success 200 {object} response.DataSubscriptionsResponse
+// @Failure 401,500 {object} response.ErrorResponse
+// @Router /subscriptions [get]
+func (h *Handler) GetSubscriptions(c *gin.Context) {
+	subs, err := h.usecase.GetSubscriptions(c.Request.Context(), middleware.CurrentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not fetch subscriptions"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": subs})
+}
+
+// Subscribe godoc
+// @Summary Follow a topic or watch a post
+// @Description Followers of a topic are notified about new posts in it; watchers of a post about new comments on it.
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param input body notification.SubscriptionInput true "Exactly one of topic_id and post_id"
+// @Success 200 {object} response.MessageResponse
+// @Failure 400,401,404,500 {object} response.ErrorResponse
+// @Router /subscriptions [post]
+func (h *Handler) Subscribe(c *gin.Context) {
+	var input SubscriptionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+
+	err := h.usecase.Subscribe(c.Request.Context(), middleware.CurrentUserID(c), input.TopicID, input.PostID)
+	switch {
+	case errors.Is(err, notification.ErrInvalidTarget):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "exactly one of topic_id and post_id is required"})
+		return
+	case errors.Is(err, notification.ErrTargetNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "topic or post not found"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not subscribe"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "subscribed"})
+}
+
+// Unsubscribe godoc
+// @Summary Stop following a topic or watching a post
+// @Tags Notifications
+// @Produce json
+// @Param topic_id query int false "Topic ID"
+// @Param post_id query int false "Post ID"
+// @Success 200 {object} response.MessageResponse
+// @Failure 400,401,404,500 {object} response.ErrorResponse
+// @Router /subscriptions [delete]
+func (h *Handler) Unsubscribe(c *gin.Context) {
+	topicID, errTopic := response.OptionalInt(c, "topic_id")
+	postID, errPost := response.OptionalInt(c, "post_id")
+	if errTopic != nil || errPost != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid topic_id or post_id"})
+		return
+	}
+
+	found, err := h.usecase.Unsubscribe(c.Request.Context(), middleware.CurrentUserID(c), topicID, postID)
+	switch {
+	case errors.Is(err, notification.ErrInvalidTarget):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "exactly one of topic_id and post_id is required"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not unsubscribe"})
+		return
+	case !found:
+		c.JSON(http.StatusNotFound, gin.H{"error": "subscription not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "unsubscribed"})
+}
@@ -1,10 +1,15 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/post"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/tag"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/topic"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/trash"
+	response "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/handler"
 	PostUC "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/usecase/post"
 	"go.uber.org/zap"
 
@@ -17,62 +22,147 @@ type PostHandler struct {
 }
 
 type CreatePostInput struct {
-	TopicID int    `json:"topic_id"`
-	Title   string `json:"title"`
-	Content string `json:"content"`
+	TopicID int      `json:"topic_id"`
+	Title   string   `json:"title"`
+	Content string   `json:"content"`
+	Tags    []string `json:"tags"` // at most 5; "Error Handling" is stored as "error-handling"
+}
+
+type UpdatePostInput struct {
+	Title   string `json:"title" binding:"required"`
+	Content string `json:"content" binding:"required"`
 }
 
 func NewPostHandler(r *gin.Engine, uc *PostUC.UseCase, authMiddleware gin.HandlerFunc, logger *zap.Logger) {
 	h := &PostHandler{uc: uc, logger: logger}
 
 	r.GET("/posts/all", h.getAll)
-	r.GET("/posts", h.getByTopic)
+	r.GET("/posts", h.list)
+	r.GET("/posts/revisions", h.getRevisions)
+	r.GET("/posts/revisions/diff", h.diffRevisions)
+	r.GET("/posts/:id", h.getByID)
 
 	auth := r.Group("/", authMiddleware)
 	auth.POST("/posts/create", h.create)
+	auth.PUT("/posts/update", h.update)
 	auth.DELETE("/posts/delete", h.delete)
+	auth.POST("/posts/restore", h.restore)
+	auth.PUT("/posts/state", h.setState)
+	auth.POST("/posts/accept", h.accept)
+	auth.DELETE("/posts/accept", h.unaccept)
 }
 
 // getAll godoc
 // @Summary Get all posts
+// @Description Archived posts are left out unless archived=true.
 // @Tags Posts
 // @Produce json
+// @Param archived query bool false "List archived posts instead of the others"
+// @Param cursor query string false "Cursor from next_cursor of the previous page"
+// @Param limit query int false "Page size"
 // @Success 200 {object} response.DataPostsResponse
-// @Failure 500 {object} response.ErrorResponse
+// @Failure 400,500 {object} response.ErrorResponse
 // @Router /posts/all [get]
 func (h *PostHandler) getAll(c *gin.Context) {
-	posts, err := h.uc.GetAll(c.Request.Context())
+	page, ok := response.Page(c, "invalid cursor or limit")
+	if !ok {
+		return
+	}
+
+	posts, next, err := h.uc.List(c.Request.Context(), post.Filter{Archived: c.Query("archived") == "true"}, page)
 	if err != nil {
 		h.logger.Error("failed to get posts", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get posts"})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"data": posts})
+	response.List(c, posts, next)
 }
 
-// getByTopic godoc
-// @Summary Get posts by topic
+// list godoc
+// @Summary Get posts by topic, tag and/or answer state
+// @Description Pinned posts of a topic come first. Archived posts are left out unless archived=true.
 // @Tags Posts
 // @Produce json
-// @Param topic_id query int true "Topic ID"
+// @Param topic_id query int false "Topic ID"
+// @Param tag query string false "Tag name"
+// @Param answered query bool false "true: posts with an accepted answer; false: unanswered questions in Q&A topics"
+// @Param archived query bool false "List archived posts instead of the others"
+// @Param cursor query string false "Cursor from next_cursor of the previous page"
+// @Param limit query int false "Page size"
 // @Success 200 {object} response.DataPostsResponse
 // @Failure 400,500 {object} response.ErrorResponse
 // @Router /posts [get]
-func (h *PostHandler) getByTopic(c *gin.Context) {
-	topicIDStr := c.Query("topic_id")
-	topicID, err := strconv.Atoi(topicIDStr)
-	if err != nil {
-		h.logger.Error("invalid topic_id", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid topic_id"})
+func (h *PostHandler) list(c *gin.Context) {
+	f := post.Filter{Tag: c.Query("tag"), Archived: c.Query("archived") == "true"}
+	if v := c.Query("topic_id"); v != "" {
+		topicID, err := strconv.Atoi(v)
+		if err != nil {
+			h.logger.Error("invalid topic_id", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid topic_id"})
+			return
+		}
+		f.TopicID = &topicID
+	}
+	if v := c.Query("answered"); v != "" {
+		answered, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid answered"})
+			return
+		}
+		f.Answered = &answered
+	}
+	if f.TopicID == nil && f.Tag == "" && f.Answered == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "topic_id, tag or answered is required"})
 		return
 	}
+	if f.Tag != "" {
+		name, err := tag.Normalize(f.Tag)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tag"})
+			return
+		}
+		f.Tag = name
+	}
 
-	posts, err := h.uc.GetByTopic(c.Request.Context(), topicID)
+	page, ok := response.Page(c, "invalid cursor or limit")
+	if !ok {
+		return
+	}
+
+	posts, next, err := h.uc.List(c.Request.Context(), f, page)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get posts"})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"data": posts})
+	response.List(c, posts, next)
+}
+
+// getByID godoc
+// @Summary Get a single post with its topic and comments
+// @Tags Posts
+// @Produce json
+// @Param id path int true "Post ID"
+// @Success 200 {object} response.DataPostDetailsResponse
+// @Failure 400,404,500 {object} response.ErrorResponse
+// @Router /posts/{id} [get]
+func (h *PostHandler) getByID(c *gin.Context) {
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.logger.Error("invalid post id", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post id"})
+		return
+	}
+
+	details, err := h.uc.GetByID(c.Request.Context(), postID)
+	switch {
+	case errors.Is(err, post.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "post not found"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get post"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": details})
 }
 
 // create godoc
@@ -82,7 +172,7 @@ func (h *PostHandler) getByTopic(c *gin.Context) {
 // @Produce json
 // @Param post body CreatePostInput true "Post payload"
 // @Success 200 {object} response.MessageResponse
-// @Failure 400,500 {object} response.ErrorResponse
+// @Failure 400,404,500 {object} response.ErrorResponse
 // @Router /posts/create [post]
 func (h *PostHandler) create(c *gin.Context) {
 	var req CreatePostInput
@@ -100,23 +190,190 @@ func (h *PostHandler) create(c *gin.Context) {
 		Title:    req.Title,
 		Content:  req.Content,
 		Username: username,
+		Tags:     req.Tags,
 	}
 
 	err := h.uc.Create(c.Request.Context(), p)
-	if err != nil {
+	switch {
+	case errors.Is(err, topic.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "topic not found"})
+		return
+	case errors.Is(err, tag.ErrInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tags may only hold letters, digits and + # . _ - and be up to 32 characters"})
+		return
+	case errors.Is(err, tag.ErrTooMany):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "too many tags"})
+		return
+	case errors.Is(err, tag.ErrNotAllowed):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tag is not on the allowlist"})
+		return
+	case err != nil:
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create post"})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "post created"})
 }
 
+// update godoc
+// @Summary Edit a post (author or admin)
+// @Tags Posts
+// @Accept json
+// @Produce json
+// @Param post_id query int true "Post ID"
+// @Param post body UpdatePostInput true "New title and content"
+// @Success 200 {object} response.MessageResponse
+// @Failure 400,403,404,409,500 {object} response.ErrorResponse
+// @Router /posts/update [put]
+func (h *PostHandler) update(c *gin.Context) {
+	postID, err := strconv.Atoi(c.Query("post_id"))
+	if err != nil {
+		h.logger.Error("invalid post_id", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post_id"})
+		return
+	}
+
+	var req UpdatePostInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("invalid request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	username := c.GetString("username")
+	role := c.GetString("role")
+
+	err = h.uc.Update(c.Request.Context(), postID, username, role, req.Title, req.Content)
+	switch {
+	case errors.Is(err, post.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "post not found"})
+		return
+	case errors.Is(err, post.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "only the author or admin can edit this post"})
+		return
+	case errors.Is(err, post.ErrArchived):
+		c.JSON(http.StatusConflict, gin.H{"error": "post is archived and read-only"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update post"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "post updated"})
+}
+
+// setState godoc
+// @Summary Pin, lock or archive a post (admin only)
+// @Description Pinned posts come first in their topic; locked posts take no new comments; archived posts are read-only and left out of post lists. Omitted fields are left as they are.
+// @Tags Posts
+// @Accept json
+// @Produce json
+// @Param post_id query int true "Post ID"
+// @Param state body post.State true "New state"
+// @Success 200 {object} response.DataPostResponse
+// @Failure 400,403,404,500 {object} response.ErrorResponse
+// @Router /posts/state [put]
+func (h *PostHandler) setState(c *gin.Context) {
+	postID, err := strconv.Atoi(c.Query("post_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post_id"})
+		return
+	}
+
+	var s post.State
+	if err := c.ShouldBindJSON(&s); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	p, err := h.uc.SetState(c.Request.Context(), postID, s, c.GetString("username"), c.GetString("role"))
+	switch {
+	case errors.Is(err, post.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admin can change the state of posts"})
+		return
+	case errors.Is(err, post.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "post not found"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to change post state"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": p})
+}
+
+// getRevisions godoc
+// @Summary Get the revision history of a post
+// @Tags Posts
+// @Produce json
+// @Param post_id query int true "Post ID"
+// @Success 200 {object} response.DataRevisionsResponse
+// @Failure 400,404,500 {object} response.ErrorResponse
+// @Router /posts/revisions [get]
+func (h *PostHandler) getRevisions(c *gin.Context) {
+	postID, err := strconv.Atoi(c.Query("post_id"))
+	if err != nil {
+		h.logger.Error("invalid post_id", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post_id"})
+		return
+	}
+
+	revisions, err := h.uc.GetRevisions(c.Request.Context(), postID)
+	switch {
+	case errors.Is(err, post.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "post not found"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get revisions"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": revisions})
+}
+
+// diffRevisions godoc
+// @Summary Diff two revisions of a post
+// @Tags Posts
+// @Produce json
+// @Param post_id query int true "Post ID"
+// @Param from query int true "Old version"
+// @Param to query int true "New version"
+// @Success 200 {object} response.DataRevisionDiffResponse
+// @Failure 400,404,422,500 {object} response.ErrorResponse
+// @Router /posts/revisions/diff [get]
+func (h *PostHandler) diffRevisions(c *gin.Context) {
+	postID, err := strconv.Atoi(c.Query("post_id"))
+	if err != nil {
+		h.logger.Error("invalid post_id", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post_id"})
+		return
+	}
+	from, errFrom := strconv.Atoi(c.Query("from"))
+	to, errTo := strconv.Atoi(c.Query("to"))
+	if errFrom != nil || errTo != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from/to version"})
+		return
+	}
+
+	diff, err := h.uc.DiffRevisions(c.Request.Context(), postID, from, to)
+	switch {
+	case errors.Is(err, post.ErrRevisionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "revision not found"})
+		return
+	case errors.Is(err, post.ErrDiffTooLarge):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "revisions differ too much to diff"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to diff revisions"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": diff})
+}
+
 // delete godoc
-// @Summary Delete post by ID (admin only)
+// @Summary Move a post to the trash (admin only)
+// @Description Its comments go to the trash with it and come back when it is restored.
 // @Tags Posts
 // @Produce json
 // @Param post_id query int true "Post ID"
 // @Success 200 {object} response.MessageResponse
-// @Failure 400,403,500 {object} response.ErrorResponse
+// @Failure 400,403,404,500 {object} response.ErrorResponse
 // @Router /posts/delete [delete]
 func (h *PostHandler) delete(c *gin.Context) {
 	roleAny, _ := c.Get("role")
@@ -133,11 +390,113 @@ func (h *PostHandler) delete(c *gin.Context) {
 		return
 	}
 
-	err = h.uc.Delete(c.Request.Context(), postID)
-	if err != nil {
+	err = h.uc.Delete(c.Request.Context(), postID, c.GetString("username"))
+	switch {
+	case errors.Is(err, post.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "post not found"})
+		return
+	case err != nil:
 		h.logger.Error("failed to delete post", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete post"})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "post deleted"})
 }
+
+// restore godoc
+// @Summary Restore a post from the trash (admin only)
+// @Description Comments deleted together with the post are restored too. Posts of a deleted topic come back when the topic is restored.
+// @Tags Posts
+// @Produce json
+// @Param post_id query int true "Post ID"
+// @Success 200 {object} response.MessageResponse
+// @Failure 400,403,404,409,500 {object} response.ErrorResponse
+// @Router /posts/restore [post]
+func (h *PostHandler) restore(c *gin.Context) {
+	if c.GetString("role") != "ADMIN" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admin can restore posts"})
+		return
+	}
+
+	postID, err := strconv.Atoi(c.Query("post_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post_id"})
+		return
+	}
+
+	err = h.uc.Restore(c.Request.Context(), postID)
+	switch {
+	case errors.Is(err, trash.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "post is not in the trash"})
+		return
+	case errors.Is(err, trash.ErrParentDeleted):
+		c.JSON(http.StatusConflict, gin.H{"error": "topic of the post is in the trash"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to restore post"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "post restored"})
+}
+
+// accept godoc
+// @Summary Accept a comment as the answer to a post (author or admin)
+// @Description Only in Q&A topics. Replaces an earlier accepted answer. The accepted answer is listed first among the post's comments.
+// @Tags Posts
+// @Produce json
+// @Param post_id query int true "Post ID"
+// @Param comment_id query int true "Comment ID"
+// @Success 200 {object} response.MessageResponse
+// @Failure 400,403,404,409,500 {object} response.ErrorResponse
+// @Router /posts/accept [post]
+func (h *PostHandler) accept(c *gin.Context) {
+	postID, errPost := strconv.Atoi(c.Query("post_id"))
+	commentID, errComment := strconv.Atoi(c.Query("comment_id"))
+	if errPost != nil || errComment != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post_id or comment_id"})
+		return
+	}
+	h.setAccepted(c, postID, &commentID, "answer accepted")
+}
+
+// unaccept godoc
+// @Summary Withdraw the accepted answer of a post (author or admin)
+// @Tags Posts
+// @Produce json
+// @Param post_id query int true "Post ID"
+// @Success 200 {object} response.MessageResponse
+// @Failure 400,403,404,409,500 {object} response.ErrorResponse
+// @Router /posts/accept [delete]
+func (h *PostHandler) unaccept(c *gin.Context) {
+	postID, err := strconv.Atoi(c.Query("post_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post_id"})
+		return
+	}
+	h.setAccepted(c, postID, nil, "answer withdrawn")
+}
+
+func (h *PostHandler) setAccepted(c *gin.Context, postID int, commentID *int, message string) {
+	err := h.uc.Accept(c.Request.Context(), postID, commentID, c.GetString("username"), c.GetString("role"))
+	switch {
+	case errors.Is(err, post.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "post not found"})
+		return
+	case errors.Is(err, post.ErrAnswerNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "comment not found on this post"})
+		return
+	case errors.Is(err, post.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "only the author or admin can accept an answer"})
+		return
+	case errors.Is(err, post.ErrNotQA):
+		c.JSON(http.StatusConflict, gin.H{"error": "post is not in a Q&A topic"})
+		return
+	case errors.Is(err, post.ErrArchived):
+		c.JSON(http.StatusConflict, gin.H{"error": "post is archived and read-only"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to accept answer"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
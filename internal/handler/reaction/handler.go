@@ -0,0 +1,106 @@
+package reaction
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/reaction"
+	response "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/handler"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/middleware"
+	usecase "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/usecase/reaction"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	usecase *usecase.UseCase
+	logger  *zap.Logger
+}
+
+type ToggleInput struct {
+	PostID    *int   `json:"post_id"`
+	CommentID *int   `json:"comment_id"`
+	MessageID *int   `json:"message_id"`
+	Emoji     string `json:"emoji" binding:"required"` // one of 👍 👎 ❤️ 😂 😮 😢 🎉 🚀
+}
+
+func NewReactionHandler(rg *gin.RouterGroup, uc *usecase.UseCase, authMiddleware gin.HandlerFunc, logger *zap.Logger) {
+	h := &Handler{usecase: uc, logger: logger}
+
+	rg.GET("/reactions", h.List)
+	rg.POST("/reactions", authMiddleware, h.Toggle)
+}
+
+// Toggle godoc
+// @Summary Add or remove a reaction
+// @Description Reacting twice with the same emoji removes the reaction. Reactions to chat messages are pushed to the room as "message.reaction" frames over the /chat WebSocket and /chat/events.
+// @Tags Reactions
+// @Accept json
+// @Produce json
+// @Param input body reaction.ToggleInput true "Exactly one of post_id, comment_id and message_id, and the emoji"
+// @Success 200 {object} response.ReactionResponse
+// @Failure 400,401,403,404,409,500 {object} response.ErrorResponse
+// @Router /reactions [post]
+func (h *Handler) Toggle(c *gin.Context) {
+	var input ToggleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+
+	t := reaction.Target{PostID: input.PostID, CommentID: input.CommentID, MessageID: input.MessageID}
+	res, err := h.usecase.Toggle(c.Request.Context(), t, middleware.CurrentUserID(c), c.GetString("username"), input.Emoji)
+	switch {
+	case errors.Is(err, reaction.ErrInvalidTarget):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "exactly one of post_id, comment_id and message_id is required"})
+		return
+	case errors.Is(err, reaction.ErrInvalidEmoji):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "emoji is not one of the allowed reactions"})
+		return
+	case errors.Is(err, reaction.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "banned from this room"})
+		return
+	case errors.Is(err, reaction.ErrTargetNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "post, comment or message not found"})
+		return
+	case errors.Is(err, reaction.ErrArchived):
+		c.JSON(http.StatusConflict, gin.H{"error": "post is archived and read-only"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not toggle reaction"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"reacted": res.Reacted, "reactions": res.Reactions})
+}
+
+// List godoc
+// @Summary List who reacted to a post, comment or chat message
+// @Description Grouped by emoji, most used first; usernames are in the order they reacted.
+// @Tags Reactions
+// @Produce json
+// @Param post_id query int false "Post ID"
+// @Param comment_id query int false "Comment ID"
+// @Param message_id query int false "Chat message ID"
+// @Success 200 {object} response.DataReactionsResponse
+// @Failure 400,500 {object} response.ErrorResponse
+// @Router /reactions [get]
+func (h *Handler) List(c *gin.Context) {
+	postID, errPost := response.OptionalInt(c, "post_id")
+	commentID, errComment := response.OptionalInt(c, "comment_id")
+	messageID, errMessage := response.OptionalInt(c, "message_id")
+	if errPost != nil || errComment != nil || errMessage != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post_id, comment_id or message_id"})
+		return
+	}
+
+	reactions, err := h.usecase.List(c.Request.Context(), reaction.Target{PostID: postID, CommentID: commentID, MessageID: messageID})
+	switch {
+	case errors.Is(err, reaction.ErrInvalidTarget):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "exactly one of post_id, comment_id and message_id is required"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not fetch reactions"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": reactions})
+}
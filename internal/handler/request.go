@@ -0,0 +1,38 @@
+package response
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/pagination"
+	"github.com/gin-gonic/gin"
+)
+
+// OptionalInt parses an integer query parameter. A missing one yields nil.
+func OptionalInt(c *gin.Context, key string) (*int, error) {
+	v := c.Query(key)
+	if v == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// Page reads the "cursor" and "limit" query parameters. When they are invalid
+// it responds with 400 and errText and returns false.
+func Page(c *gin.Context, errText string) (pagination.Page, bool) {
+	page, err := pagination.FromQuery(c.Query("cursor"), c.Query("limit"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errText})
+		return pagination.Page{}, false
+	}
+	return page, true
+}
+
+// List responds with one page of a list and the cursor of the next one.
+func List(c *gin.Context, data any, next string) {
+	c.JSON(http.StatusOK, gin.H{"data": data, "next_cursor": next})
+}
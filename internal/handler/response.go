@@ -8,27 +8,216 @@ type MessageResponse struct {
 	Message string `json:"message"` // Сообщение
 }
 
+type UnreadResponse struct {
+	Unread int `json:"unread"` // Число непрочитанных сообщений
+}
+
+type DataNotificationsResponse struct {
+	Data       []Notification `json:"data"`        // Уведомления
+	NextCursor string         `json:"next_cursor"` // Курсор следующей страницы, пустой на последней
+}
+
+type DataSearchResponse struct {
+	Data       []SearchResult `json:"data"`        // Найденные посты и комментарии
+	NextCursor string         `json:"next_cursor"` // Курсор следующей страницы, пустой на последней
+}
+
+type DataMentionsResponse struct {
+	Data       []Mention `json:"data"`        // Упоминания
+	NextCursor string    `json:"next_cursor"` // Курсор следующей страницы, пустой на последней
+}
+
+type DataTrashResponse struct {
+	Data       []TrashItem `json:"data"`        // Удалённые темы, посты и комментарии, последние удалённые первыми
+	NextCursor string      `json:"next_cursor"` // Курсор следующей страницы, пустой на последней
+}
+
+type DataTagsResponse struct {
+	Data []Tag `json:"data"` // Теги с числом постов, самые популярные первыми
+}
+
+type TagResponse struct {
+	Name string `json:"name"` // Нормализованное имя тега
+}
+
+type ReactionResponse struct {
+	Reacted   bool           `json:"reacted"`   // Стоит ли теперь реакция пользователя
+	Reactions map[string]int `json:"reactions"` // Число пользователей по каждому эмодзи
+}
+
+type DataReactionsResponse struct {
+	Data []Reaction `json:"data"` // Реакции по эмодзи, самые частые первыми
+}
+
+type Reaction struct {
+	Emoji     string   `json:"emoji"`
+	Count     int      `json:"count"`
+	Usernames []string `json:"usernames"` // В порядке добавления реакций
+}
+
+type DataSubscriptionsResponse struct {
+	Data []Subscription `json:"data"` // Подписки на темы и посты
+}
+
 type DataCommentsResponse struct {
-	Data []Comment `json:"data"` // Комментарии
+	Data       []Comment `json:"data"`        // Комментарии
+	NextCursor string    `json:"next_cursor"` // Курсор следующей страницы, пустой на последней
 }
 
 type DataPostsResponse struct {
-	Data []Post `json:"data"` // Посты
+	Data       []Post `json:"data"`        // Посты
+	NextCursor string `json:"next_cursor"` // Курсор следующей страницы, пустой на последней
+}
+
+type DataTopicsResponse struct {
+	Data       []Topic `json:"data"`        // Темы
+	NextCursor string  `json:"next_cursor"` // Курсор следующей страницы, пустой на последней
 }
 
 type Comment struct {
+	ID        int            `json:"id"`
+	PostID    int            `json:"post_id"`
+	ParentID  *int           `json:"parentId,omitempty"`
+	Depth     int            `json:"depth"`
+	Content   string         `json:"content"`
+	Username  string         `json:"username"`
+	Deleted   bool           `json:"deleted,omitempty"`
+	Reactions map[string]int `json:"reactions"`          // Число пользователей по каждому эмодзи
+	Accepted  bool           `json:"accepted,omitempty"` // Принятый ответ на вопрос
+	Timestamp string         `json:"timestamp"`
+	Replies   []Comment      `json:"replies,omitempty"`
+}
+
+type Notification struct {
 	ID        int    `json:"id"`
-	PostID    int    `json:"post_id"`
-	Content   string `json:"content"`
+	UserID    int32  `json:"user_id"`
+	Kind      string `json:"kind"` // comment, reply, post или mention
+	Actor     string `json:"actor"`
+	PostID    *int   `json:"post_id,omitempty"`
+	CommentID *int   `json:"comment_id,omitempty"`
+	RoomID    *int   `json:"room_id,omitempty"`
+	MessageID *int   `json:"message_id,omitempty"`
+	Title     string `json:"title"`
+	Excerpt   string `json:"excerpt"`
+	ReadAt    string `json:"read_at,omitempty"`
+	Timestamp string `json:"timestamp"`
+}
+
+type SearchResult struct {
+	Type      string  `json:"type"` // post или comment
+	ID        int     `json:"id"`
+	PostID    int     `json:"post_id"`
+	TopicID   int     `json:"topic_id"`
+	Title     string  `json:"title"`
+	Headline  string  `json:"headline"` // Экранированный HTML-фрагмент с найденными словами в <mark></mark>
+	Username  string  `json:"username"`
+	Timestamp string  `json:"timestamp"`
+	Rank      float32 `json:"rank"`
+}
+
+type Mention struct {
+	ID        int    `json:"id"`
+	UserID    int32  `json:"user_id"`
 	Username  string `json:"username"`
+	Actor     string `json:"actor"`
+	PostID    *int   `json:"post_id,omitempty"`
+	CommentID *int   `json:"comment_id,omitempty"`
+	RoomID    *int   `json:"room_id,omitempty"`
+	MessageID *int   `json:"message_id,omitempty"`
+	Excerpt   string `json:"excerpt"`
 	Timestamp string `json:"timestamp"`
 }
 
+type Subscription struct {
+	TopicID   *int   `json:"topic_id,omitempty"`
+	PostID    *int   `json:"post_id,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
 type Post struct {
+	ID                int            `json:"id"`
+	TopicID           int            `json:"topic_id"`
+	Title             string         `json:"title"`
+	Content           string         `json:"content"`
+	Username          string         `json:"username"`
+	Tags              []string       `json:"tags"`                          // Теги поста по алфавиту
+	Reactions         map[string]int `json:"reactions"`                     // Число пользователей по каждому эмодзи
+	AcceptedCommentID *int           `json:"accepted_comment_id,omitempty"` // Принятый ответ в теме вопросов и ответов
+	Pinned            bool           `json:"pinned,omitempty"`              // Закреплён вверху темы
+	Locked            bool           `json:"locked,omitempty"`              // Закрыт для новых комментариев
+	Archived          bool           `json:"archived,omitempty"`            // В архиве: только для чтения
+	Timestamp         string         `json:"timestamp"`
+	UpdatedAt         string         `json:"updated_at,omitempty"`
+}
+
+type TrashItem struct {
+	Type      string `json:"type"`               // topic, post или comment
+	ID        int    `json:"id"`                 // ID темы, поста или комментария
+	TopicID   *int   `json:"topic_id,omitempty"` // Тема поста или комментария
+	PostID    *int   `json:"post_id,omitempty"`  // Пост комментария
+	Title     string `json:"title"`              // Заголовок; для комментария — заголовок его поста
+	Excerpt   string `json:"excerpt"`            // Начало описания или текста
+	Username  string `json:"username,omitempty"` // Автор; пустой у тем
+	DeletedAt string `json:"deleted_at"`         // Время удаления
+	DeletedBy string `json:"deleted_by"`         // Кто удалил
+	PurgeAt   string `json:"purge_at"`           // Когда будет удалено навсегда
+}
+
+type Tag struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"` // Число постов с тегом
+}
+
+type DataPostResponse struct {
+	Data Post `json:"data"` // Пост
+}
+
+type DataPostDetailsResponse struct {
+	Data PostDetails `json:"data"` // Пост с темой и комментариями
+}
+
+type Topic struct {
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	IsQA        bool   `json:"is_qa"` // Тема вопросов и ответов
+	CreatedAt   string `json:"created_at"`
+}
+
+type PostDetails struct {
+	Post               Post      `json:"post"`
+	Topic              Topic     `json:"topic"`
+	Comments           []Comment `json:"comments"`                       // Первая страница дерева комментариев
+	CommentsNextCursor string    `json:"comments_next_cursor,omitempty"` // Курсор для /comments/thread, пустой если комментариев больше нет
+}
+
+type DataRevisionsResponse struct {
+	Data []Revision `json:"data"` // Версии поста
+}
+
+type DataRevisionDiffResponse struct {
+	Data RevisionDiff `json:"data"` // Разница между версиями
+}
+
+type Revision struct {
 	ID        int    `json:"id"`
-	TopicID   int    `json:"topic_id"`
+	PostID    int    `json:"post_id"`
+	Version   int    `json:"version"`
 	Title     string `json:"title"`
 	Content   string `json:"content"`
 	Username  string `json:"username"`
 	Timestamp string `json:"timestamp"`
 }
+
+type DiffLine struct {
+	Op   string `json:"op"` // "=", "+" или "-"
+	Text string `json:"text"`
+}
+
+type RevisionDiff struct {
+	PostID  int        `json:"post_id"`
+	From    int        `json:"from"`
+	To      int        `json:"to"`
+	Title   []DiffLine `json:"title"`
+	Content []DiffLine `json:"content"`
+}
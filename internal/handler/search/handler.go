@@ -0,0 +1,99 @@
+package search
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/search"
+	response "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/handler"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/pagination"
+	usecase "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/usecase/search"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	usecase *usecase.UseCase
+	logger  *zap.Logger
+}
+
+func NewSearchHandler(rg *gin.RouterGroup, uc *usecase.UseCase, logger *zap.Logger) {
+	h := &Handler{usecase: uc, logger: logger}
+
+	rg.GET("/search", h.Search)
+}
+
+// Search godoc
+// @Summary Full-text search over posts and comments
+// @Description Supports web search syntax: "quoted phrases", OR and -excluded words. Russian and English words are matched by their stems. Results are ordered by rank; headline is HTML-escaped text with matched words in <mark></mark>.
+// @Tags Search
+// @Produce json
+// @Param q query string true "Search text"
+// @Param type query string false "post or comment; both when omitted"
+// @Param topic_id query int false "Only this topic"
+// @Param author query string false "Only this author's username"
+// @Param from query string false "Created at or after, RFC 3339 or YYYY-MM-DD"
+// @Param to query string false "Created before, RFC 3339 or YYYY-MM-DD"
+// @Param cursor query string false "Cursor from next_cursor of the previous page"
+// @Param limit query int false "Page size"
+// @Success 200 {object} response.DataSearchResponse
+// @Failure 400,500 {object} response.ErrorResponse
+// @Router /search [get]
+func (h *Handler) Search(c *gin.Context) {
+	q := search.Query{Text: c.Query("q"), Type: c.Query("type"), Author: c.Query("author")}
+
+	if v := c.Query("topic_id"); v != "" {
+		topicID, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid topic_id"})
+			return
+		}
+		q.TopicID = &topicID
+	}
+	var errFrom, errTo error
+	q.From, errFrom = parseDate(c.Query("from"))
+	q.To, errTo = parseDate(c.Query("to"))
+	if errFrom != nil || errTo != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from or to"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil || limit < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return
+	}
+
+	results, next, err := h.usecase.Search(c.Request.Context(), q, c.Query("cursor"), limit)
+	switch {
+	case errors.Is(err, search.ErrEmptyQuery):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	case errors.Is(err, search.ErrInvalidType):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be post or comment"})
+		return
+	case errors.Is(err, pagination.ErrInvalidCursor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "search failed"})
+		return
+	}
+	response.List(c, results, next)
+}
+
+// parseDate accepts RFC 3339 timestamps and plain dates. An empty string yields nil.
+func parseDate(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t, err = time.Parse(time.DateOnly, s)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
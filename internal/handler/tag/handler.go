@@ -0,0 +1,125 @@
+package tag
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/tag"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/middleware"
+	usecase "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/usecase/tag"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	usecase *usecase.UseCase
+	logger  *zap.Logger
+}
+
+type CreateTagInput struct {
+	Name string `json:"name" binding:"required"`
+}
+
+func NewTagHandler(rg *gin.RouterGroup, uc *usecase.UseCase, authMiddleware gin.HandlerFunc, logger *zap.Logger) {
+	h := &Handler{usecase: uc, logger: logger}
+
+	rg.GET("/tags", h.List)
+	rg.GET("/tags/autocomplete", h.Autocomplete)
+	rg.POST("/tags", authMiddleware, middleware.RequireAdmin(), h.Create)
+	rg.DELETE("/tags", authMiddleware, middleware.RequireAdmin(), h.Delete)
+}
+
+// List godoc
+// @Summary List tags with their post counts
+// @Description Most used first. Filter posts by a tag with GET /posts?tag=.
+// @Tags Tags
+// @Produce json
+// @Param limit query int false "How many tags to return"
+// @Success 200 {object} response.DataTagsResponse
+// @Failure 400,500 {object} response.ErrorResponse
+// @Router /tags [get]
+func (h *Handler) List(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil || limit < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return
+	}
+
+	tags, err := h.usecase.List(c.Request.Context(), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not fetch tags"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": tags})
+}
+
+// Autocomplete godoc
+// @Summary Suggest tags starting with a prefix
+// @Tags Tags
+// @Produce json
+// @Param prefix query string true "Beginning of the tag name"
+// @Success 200 {object} response.DataTagsResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /tags/autocomplete [get]
+func (h *Handler) Autocomplete(c *gin.Context) {
+	tags, err := h.usecase.Autocomplete(c.Request.Context(), c.Query("prefix"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not fetch tags"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": tags})
+}
+
+// Create godoc
+// @Summary Add a tag to the allowlist (ADMIN)
+// @Description Only needed when TAGS_ALLOWLIST is on; otherwise tags are created with the first post using them.
+// @Tags Tags
+// @Accept json
+// @Produce json
+// @Param input body CreateTagInput true "Tag name"
+// @Success 201 {object} response.TagResponse
+// @Failure 400,401,403,409,500 {object} response.ErrorResponse
+// @Router /tags [post]
+func (h *Handler) Create(c *gin.Context) {
+	var input CreateTagInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+
+	name, err := h.usecase.Create(c.Request.Context(), input.Name)
+	switch {
+	case errors.Is(err, tag.ErrInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tags may only hold letters, digits and + # . _ - and be up to 32 characters"})
+		return
+	case errors.Is(err, tag.ErrExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "tag already exists"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not create tag"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"name": name})
+}
+
+// Delete godoc
+// @Summary Delete a tag and remove it from all posts (ADMIN)
+// @Tags Tags
+// @Produce json
+// @Param name query string true "Tag name"
+// @Success 200 {object} response.MessageResponse
+// @Failure 401,403,404,500 {object} response.ErrorResponse
+// @Router /tags [delete]
+func (h *Handler) Delete(c *gin.Context) {
+	err := h.usecase.Delete(c.Request.Context(), c.Query("name"))
+	switch {
+	case errors.Is(err, tag.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "tag not found"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not delete tag"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "tag deleted"})
+}
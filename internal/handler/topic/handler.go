@@ -1,9 +1,14 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
+	domain "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/topic"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/trash"
+	response "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/handler"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/middleware"
 	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/usecase/topic"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -18,36 +23,52 @@ func NewTopicHandler(rg *gin.RouterGroup, uc *topic.UseCase, authMiddleware gin.
 	h := &TopicHandler{UseCase: uc, logger: logger}
 
 	rg.GET("/topics", h.GetAll)
-	rg.POST("/topics/create", authMiddleware, h.RequireAdmin(), h.Create)
-	rg.DELETE("/topics/delete", authMiddleware, h.RequireAdmin(), h.Delete)
+	rg.POST("/topics/create", authMiddleware, middleware.RequireAdmin(), h.Create)
+	rg.DELETE("/topics/delete", authMiddleware, middleware.RequireAdmin(), h.Delete)
+	rg.PUT("/topics/qa", authMiddleware, middleware.RequireAdmin(), h.SetQA)
+	rg.POST("/topics/restore", authMiddleware, middleware.RequireAdmin(), h.Restore)
 }
 
 func (h *TopicHandler) RegisterRoutes(rg *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
 	rg.GET("/topics", h.GetAll)
-	rg.POST("/topics/create", authMiddleware, h.RequireAdmin(), h.Create)
-	rg.DELETE("/topics/delete", authMiddleware, h.RequireAdmin(), h.Delete)
+	rg.POST("/topics/create", authMiddleware, middleware.RequireAdmin(), h.Create)
+	rg.DELETE("/topics/delete", authMiddleware, middleware.RequireAdmin(), h.Delete)
+	rg.PUT("/topics/qa", authMiddleware, middleware.RequireAdmin(), h.SetQA)
+	rg.POST("/topics/restore", authMiddleware, middleware.RequireAdmin(), h.Restore)
 }
 
 // GetAll godoc
 // @Summary Get all topics
 // @Tags Topics
 // @Produce json
-// @Success 200 {array} topic.Topic
-// @Failure 500 {object} response.ErrorResponse
+// @Param cursor query string false "Cursor from next_cursor of the previous page"
+// @Param limit query int false "Page size"
+// @Success 200 {object} response.DataTopicsResponse
+// @Failure 400,500 {object} response.ErrorResponse
 // @Router /topics [get]
 func (h *TopicHandler) GetAll(c *gin.Context) {
-	topics, err := h.UseCase.GetAll(c.Request.Context())
+	page, ok := response.Page(c, "Некорректный курсор или лимит")
+	if !ok {
+		return
+	}
+
+	topics, next, err := h.UseCase.GetAll(c.Request.Context(), page)
 	if err != nil {
 		h.logger.Error("failed to get topics", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении тем"})
 		return
 	}
-	c.JSON(http.StatusOK, topics)
+	response.List(c, topics, next)
 }
 
 type CreateTopicInput struct {
 	Title       string `json:"title" binding:"required"`
 	Description string `json:"description" binding:"required"`
+	IsQA        bool   `json:"is_qa"` // вопросы и ответы: автор поста может принять ответ
+}
+
+type SetQAInput struct {
+	IsQA bool `json:"is_qa"`
 }
 
 // Create godoc
@@ -67,7 +88,7 @@ func (h *TopicHandler) Create(c *gin.Context) {
 		return
 	}
 
-	err := h.UseCase.Create(c.Request.Context(), input.Title, input.Description)
+	err := h.UseCase.Create(c.Request.Context(), input.Title, input.Description, input.IsQA)
 	if err != nil {
 		h.logger.Error("invalid topic input", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании темы"})
@@ -77,12 +98,13 @@ func (h *TopicHandler) Create(c *gin.Context) {
 }
 
 // Delete godoc
-// @Summary Delete a topic by ID (admin only)
+// @Summary Move a topic to the trash (admin only)
+// @Description Its posts and comments go to the trash with it and come back when it is restored.
 // @Tags Topics
 // @Produce json
 // @Param id query int true "Topic ID"
 // @Success 200 {object} response.MessageResponse
-// @Failure 400,500 {object} response.ErrorResponse
+// @Failure 400,404,500 {object} response.ErrorResponse
 // @Router /topics/delete [delete]
 func (h *TopicHandler) Delete(c *gin.Context) {
 	idStr := c.Query("id")
@@ -93,21 +115,78 @@ func (h *TopicHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	err = h.UseCase.Delete(c.Request.Context(), id)
-	if err != nil {
+	err = h.UseCase.Delete(c.Request.Context(), id, c.GetString("username"))
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Тема не найдена"})
+		return
+	case err != nil:
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении темы"})
 		return
 	}
 	c.Status(http.StatusOK)
 }
 
-func (h *TopicHandler) RequireAdmin() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		roleRaw, exists := c.Get("role")
-		if !exists || roleRaw != "ADMIN" {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Доступ запрещён: требуется роль ADMIN"})
-			return
-		}
-		c.Next()
+// Restore godoc
+// @Summary Restore a topic from the trash (admin only)
+// @Description Posts and comments deleted together with the topic are restored too.
+// @Tags Topics
+// @Produce json
+// @Param id query int true "Topic ID"
+// @Success 200 {object} response.MessageResponse
+// @Failure 400,403,404,500 {object} response.ErrorResponse
+// @Router /topics/restore [post]
+func (h *TopicHandler) Restore(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Query("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный ID"})
+		return
+	}
+
+	err = h.UseCase.Restore(c.Request.Context(), id)
+	switch {
+	case errors.Is(err, trash.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Тема не найдена в корзине"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при восстановлении темы"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Тема восстановлена"})
+}
+
+// SetQA godoc
+// @Summary Turn Q&A mode of a topic on or off (admin only)
+// @Description In Q&A topics the author of a post or an ADMIN can accept one comment as the answer.
+// @Tags Topics
+// @Accept json
+// @Produce json
+// @Param id query int true "Topic ID"
+// @Param input body SetQAInput true "Q&A mode"
+// @Success 200 {object} response.MessageResponse
+// @Failure 400,403,404,500 {object} response.ErrorResponse
+// @Router /topics/qa [put]
+func (h *TopicHandler) SetQA(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный ID"})
+		return
+	}
+
+	var input SetQAInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
+		return
+	}
+
+	err = h.UseCase.SetQA(c.Request.Context(), id, input.IsQA)
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Тема не найдена"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при изменении темы"})
+		return
 	}
+	c.JSON(http.StatusOK, gin.H{"message": "Режим вопросов и ответов изменён"})
 }
@@ -0,0 +1,53 @@
+package trash
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/trash"
+	response "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/handler"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/middleware"
+	usecase "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/usecase/trash"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	usecase *usecase.UseCase
+	logger  *zap.Logger
+}
+
+func NewTrashHandler(rg *gin.RouterGroup, uc *usecase.UseCase, authMiddleware gin.HandlerFunc, logger *zap.Logger) {
+	h := &Handler{usecase: uc, logger: logger}
+
+	rg.GET("/trash", authMiddleware, middleware.RequireAdmin(), h.List)
+}
+
+// List godoc
+// @Summary List deleted topics, posts and comments (ADMIN)
+// @Description Most recently deleted first. Items deleted together with their topic or post are not listed; restoring the topic or post brings them back. Restore with POST /topics/restore, /posts/restore or /comments/restore before purge_at.
+// @Tags Trash
+// @Produce json
+// @Param type query string false "topic, post or comment; all of them when empty"
+// @Param cursor query string false "Cursor from next_cursor of the previous page"
+// @Param limit query int false "Page size"
+// @Success 200 {object} response.DataTrashResponse
+// @Failure 400,401,403,500 {object} response.ErrorResponse
+// @Router /trash [get]
+func (h *Handler) List(c *gin.Context) {
+	page, ok := response.Page(c, "invalid cursor or limit")
+	if !ok {
+		return
+	}
+
+	items, next, err := h.usecase.List(c.Request.Context(), c.Query("type"), page)
+	switch {
+	case errors.Is(err, trash.ErrInvalidType):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be topic, post or comment"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not fetch trash"})
+		return
+	}
+	response.List(c, items, next)
+}
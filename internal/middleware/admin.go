@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireAdmin lets only users with the ADMIN role through. It must run after
+// AuthMiddleware, which sets the role.
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("role") != "ADMIN" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Доступ запрещён: требуется роль ADMIN"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -40,3 +40,11 @@ func AuthMiddleware(authClient authpb.AuthServiceClient, logger *zap.Logger) gin
 		c.Next()
 	}
 }
+
+// CurrentUserID returns the id AuthMiddleware stored for the request, or 0 if
+// the request was not authenticated.
+func CurrentUserID(c *gin.Context) int32 {
+	id, _ := c.Get("user_id")
+	userID, _ := id.(int32)
+	return userID
+}
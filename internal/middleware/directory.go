@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/AdminGo/proto/authpb"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/user"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// recordInterval is how often a user that keeps validating tokens is re-recorded.
+const recordInterval = 5 * time.Minute
+
+type UserRecorder interface {
+	Upsert(ctx context.Context, u user.User) error
+}
+
+// directoryClient records every successfully validated user in the local
+// users table, so features can resolve usernames to the auth service's user_id.
+type directoryClient struct {
+	authpb.AuthServiceClient
+	users  UserRecorder
+	seen   sync.Map // user_id -> recorded
+	logger *zap.Logger
+}
+
+type recorded struct {
+	username string
+	role     string
+	at       time.Time
+}
+
+// RecordUsers wraps an auth client so that every valid token also updates the user directory.
+func RecordUsers(client authpb.AuthServiceClient, users UserRecorder, logger *zap.Logger) authpb.AuthServiceClient {
+	return &directoryClient{AuthServiceClient: client, users: users, logger: logger}
+}
+
+func (d *directoryClient) ValidateToken(ctx context.Context, in *authpb.ValidateTokenRequest, opts ...grpc.CallOption) (*authpb.ValidateTokenResponse, error) {
+	resp, err := d.AuthServiceClient.ValidateToken(ctx, in, opts...)
+	if err != nil || !resp.GetValid() {
+		return resp, err
+	}
+
+	if prev, ok := d.seen.Load(resp.UserId); ok {
+		p := prev.(recorded)
+		if p.username == resp.Username && p.role == resp.Role && time.Since(p.at) < recordInterval {
+			return resp, nil
+		}
+	}
+
+	u := user.User{ID: resp.UserId, Username: resp.Username, Role: resp.Role}
+	if err := d.users.Upsert(ctx, u); err != nil {
+		d.logger.Error("Failed to record user", zap.Int32("userID", resp.UserId), zap.Error(err))
+		return resp, nil
+	}
+	d.seen.Store(resp.UserId, recorded{username: resp.Username, role: resp.Role, at: time.Now()})
+	return resp, nil
+}
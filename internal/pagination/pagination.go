@@ -0,0 +1,141 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor points at the last row of a page by its (timestamp, id) key. Lists
+// that put pinned rows first also key on Pinned, and lists that mix several
+// tables, whose ids may repeat, also key on Type.
+type Cursor struct {
+	Timestamp time.Time
+	ID        int
+	Pinned    bool
+	Type      string
+}
+
+// Encode returns the opaque string form of the cursor.
+func (c Cursor) Encode() string {
+	raw := c.Timestamp.UTC().Format(time.RFC3339Nano) + "|" + strconv.Itoa(c.ID)
+	if c.Pinned {
+		raw += "|p"
+	}
+	if c.Type != "" {
+		raw += "|t" + c.Type
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// Decode parses a cursor produced by Encode. An empty string yields nil.
+func Decode(s string) (*Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	t, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	fields := strings.Split(id, "|")
+	n, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	c := &Cursor{Timestamp: t, ID: n}
+	for _, f := range fields[1:] {
+		switch {
+		case f == "p" && !c.Pinned:
+			c.Pinned = true
+		case len(f) > 1 && f[0] == 't' && c.Type == "":
+			c.Type = f[1:]
+		default:
+			return nil, ErrInvalidCursor
+		}
+	}
+	return c, nil
+}
+
+// Page describes which slice of a list to return.
+type Page struct {
+	After *Cursor
+	Limit int
+}
+
+// FromQuery builds a Page from the "cursor" and "limit" query values.
+// A missing limit is left as zero so that Limits.Apply picks the default.
+func FromQuery(cursor, limit string) (Page, error) {
+	after, err := Decode(cursor)
+	if err != nil {
+		return Page{}, err
+	}
+	var n int
+	if limit != "" {
+		n, err = strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			return Page{}, errors.New("invalid limit")
+		}
+	}
+	return Page{After: after, Limit: n}, nil
+}
+
+// Limits bounds the page size a client may request.
+type Limits struct {
+	Default int
+	Max     int
+}
+
+// Apply fills in the default page size and clamps it to the maximum.
+func (l Limits) Apply(p Page) Page {
+	if p.Limit <= 0 {
+		p.Limit = l.Default
+	}
+	if l.Max > 0 && p.Limit > l.Max {
+		p.Limit = l.Max
+	}
+	return p
+}
+
+// Trim cuts a result fetched with LIMIT limit+1 down to limit rows and
+// returns the cursor of the next page, or "" if this is the last one.
+func Trim[T any](items []T, limit int, key func(T) Cursor) ([]T, string) {
+	if limit <= 0 || len(items) <= limit {
+		return items, ""
+	}
+	items = items[:limit]
+	return items, key(items[len(items)-1]).Encode()
+}
+
+// EncodeOffset returns an opaque cursor for lists without a stable sort key,
+// such as search results ordered by rank, that are paged by offset.
+func EncodeOffset(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("o|" + strconv.Itoa(offset)))
+}
+
+// DecodeOffset parses a cursor produced by EncodeOffset. An empty string yields 0.
+func DecodeOffset(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(string(raw), "o|"))
+	if err != nil || n < 0 || !strings.HasPrefix(string(raw), "o|") {
+		return 0, ErrInvalidCursor
+	}
+	return n, nil
+}
@@ -0,0 +1,73 @@
+package pagination
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	ts := time.Date(2025, 6, 1, 12, 30, 0, 123456789, time.UTC)
+	for _, c := range []Cursor{
+		{Timestamp: ts, ID: 42},
+		{Timestamp: ts, ID: 7, Pinned: true},
+		{Timestamp: ts, ID: 9, Type: "comment"},
+	} {
+		got, err := Decode(c.Encode())
+		if err != nil {
+			t.Fatalf("Decode(%+v): %v", c, err)
+		}
+		if !got.Timestamp.Equal(c.Timestamp) || got.ID != c.ID || got.Pinned != c.Pinned || got.Type != c.Type {
+			t.Fatalf("got %+v, want %+v", *got, c)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	if c, err := Decode(""); c != nil || err != nil {
+		t.Fatalf("Decode(\"\") = %v, %v; want nil, nil", c, err)
+	}
+	for _, s := range []string{
+		"not base64!",
+		"bm8tc2VwYXJhdG9y",                 // "no-separator"
+		"MjAyNS0wNi0wMXwx",                 // "2025-06-01|1", not RFC 3339
+		"MjAyNS0wNi0wMVQwMDowMDowMFp8eA",   // "2025-06-01T00:00:00Z|x"
+		"MjAyNS0wNi0wMVQwMDowMDowMFp8MXxx", // "2025-06-01T00:00:00Z|1|q"
+	} {
+		if _, err := Decode(s); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("Decode(%q) err = %v, want ErrInvalidCursor", s, err)
+		}
+	}
+}
+
+func TestTrim(t *testing.T) {
+	key := func(n int) Cursor { return Cursor{ID: n} }
+
+	items, next := Trim([]int{1, 2, 3}, 3, key)
+	if len(items) != 3 || next != "" {
+		t.Fatalf("exactly limit rows: got %v, %q; want all rows and no cursor", items, next)
+	}
+
+	items, next = Trim([]int{1, 2, 3, 4}, 3, key)
+	if len(items) != 3 {
+		t.Fatalf("limit+1 rows: got %v, want 3 rows", items)
+	}
+	c, err := Decode(next)
+	if err != nil || c.ID != 3 {
+		t.Fatalf("next cursor = %+v, %v; want id 3", c, err)
+	}
+
+	if items, next = Trim([]int{1, 2}, 0, key); len(items) != 2 || next != "" {
+		t.Fatalf("no limit: got %v, %q", items, next)
+	}
+}
+
+func TestOffsetRoundTrip(t *testing.T) {
+	n, err := DecodeOffset(EncodeOffset(40))
+	if err != nil || n != 40 {
+		t.Fatalf("got %d, %v; want 40", n, err)
+	}
+	if _, err := DecodeOffset(Cursor{ID: 1}.Encode()); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("keyset cursor as offset: err = %v, want ErrInvalidCursor", err)
+	}
+}
@@ -0,0 +1,119 @@
+package chat
+
+import (
+	"context"
+	"slices"
+
+	domain "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/chat"
+	"github.com/jackc/pgx/v5"
+)
+
+const directColumns = `m.id, m.sender_id, s.username, m.recipient_id, r.username, m.content, m.created_at, m.read_at`
+
+const directJoins = `JOIN backend_schema.users s ON s.id = m.sender_id
+	JOIN backend_schema.users r ON r.id = m.recipient_id`
+
+func scanDirect(row pgx.Row, m *domain.DirectMessage) error {
+	return row.Scan(&m.ID, &m.SenderID, &m.SenderUsername, &m.RecipientID, &m.RecipientUsername, &m.Content, &m.CreatedAt, &m.ReadAt)
+}
+
+func (r *Repository) SaveDirectMessage(ctx context.Context, senderID, recipientID int32, content string) (domain.DirectMessage, error) {
+	var msg domain.DirectMessage
+	err := scanDirect(r.db.QueryRow(ctx,
+		`WITH m AS (
+			INSERT INTO backend_schema.direct_messages (sender_id, recipient_id, content) VALUES ($1, $2, $3)
+			RETURNING *
+		)
+		SELECT `+directColumns+` FROM m `+directJoins,
+		senderID, recipientID, content), &msg)
+	return msg, err
+}
+
+// GetDirectMessages returns a window of the conversation between two users in
+// ascending id order, using the same since/before semantics as room history.
+func (r *Repository) GetDirectMessages(ctx context.Context, userID, peerID int32, q domain.HistoryQuery) ([]domain.DirectMessage, error) {
+	order := "DESC"
+	if q.SinceID > 0 {
+		order = "ASC"
+	}
+	rows, err := r.db.Query(ctx,
+		`SELECT `+directColumns+` FROM backend_schema.direct_messages m `+directJoins+`
+		 WHERE LEAST(m.sender_id, m.recipient_id) = LEAST($1::int, $2::int)
+		   AND GREATEST(m.sender_id, m.recipient_id) = GREATEST($1::int, $2::int)
+		   AND ($3 = 0 OR m.id > $3) AND ($4 = 0 OR m.id < $4)
+		 ORDER BY m.id `+order+`
+		 LIMIT $5`,
+		userID, peerID, q.SinceID, q.BeforeID, q.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []domain.DirectMessage
+	for rows.Next() {
+		var msg domain.DirectMessage
+		if err := scanDirect(rows, &msg); err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if order == "DESC" {
+		slices.Reverse(messages)
+	}
+	return messages, nil
+}
+
+// GetInbox lists a user's conversations, most recently active first.
+func (r *Repository) GetInbox(ctx context.Context, userID int32) ([]domain.Conversation, error) {
+	rows, err := r.db.Query(ctx,
+		`WITH latest AS (
+			SELECT DISTINCT ON (LEAST(sender_id, recipient_id), GREATEST(sender_id, recipient_id)) *
+			FROM backend_schema.direct_messages
+			WHERE sender_id = $1 OR recipient_id = $1
+			ORDER BY LEAST(sender_id, recipient_id), GREATEST(sender_id, recipient_id), id DESC
+		)
+		SELECT `+directColumns+`,
+			(SELECT count(*) FROM backend_schema.direct_messages u
+			 WHERE u.recipient_id = $1 AND u.sender_id = CASE WHEN m.sender_id = $1 THEN m.recipient_id ELSE m.sender_id END
+			   AND u.read_at IS NULL)
+		FROM latest m `+directJoins+`
+		ORDER BY m.id DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var inbox []domain.Conversation
+	for rows.Next() {
+		var c domain.Conversation
+		m := &c.LastMessage
+		if err := rows.Scan(&m.ID, &m.SenderID, &m.SenderUsername, &m.RecipientID, &m.RecipientUsername, &m.Content, &m.CreatedAt, &m.ReadAt, &c.Unread); err != nil {
+			return nil, err
+		}
+		if m.SenderID == userID {
+			c.UserID, c.Username = m.RecipientID, m.RecipientUsername
+		} else {
+			c.UserID, c.Username = m.SenderID, m.SenderUsername
+		}
+		inbox = append(inbox, c)
+	}
+	return inbox, rows.Err()
+}
+
+// MarkDirectRead marks every unread message from peerID to userID as read.
+func (r *Repository) MarkDirectRead(ctx context.Context, userID, peerID int32) (int64, error) {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE backend_schema.direct_messages SET read_at = now()
+		 WHERE recipient_id = $1 AND sender_id = $2 AND read_at IS NULL`, userID, peerID)
+	return tag.RowsAffected(), err
+}
+
+func (r *Repository) CountUnreadDirect(ctx context.Context, userID int32) (int, error) {
+	var n int
+	err := r.db.QueryRow(ctx,
+		`SELECT count(*) FROM backend_schema.direct_messages WHERE recipient_id = $1 AND read_at IS NULL`, userID).Scan(&n)
+	return n, err
+}
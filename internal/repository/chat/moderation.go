@@ -0,0 +1,132 @@
+package chat
+
+import (
+	"context"
+	"errors"
+
+	domain "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/chat"
+	"github.com/jackc/pgx/v5"
+)
+
+const muteColumns = `id, room_id, user_id, username, until, reason, created_by, created_at`
+
+func scanMute(row pgx.Row, m *domain.Mute) error {
+	return row.Scan(&m.ID, &m.RoomID, &m.UserID, &m.Username, &m.Until, &m.Reason, &m.CreatedBy, &m.CreatedAt)
+}
+
+const banColumns = `room_id, user_id, username, reason, created_by, created_at`
+
+func scanBan(row pgx.Row, b *domain.Ban) error {
+	return row.Scan(&b.RoomID, &b.UserID, &b.Username, &b.Reason, &b.CreatedBy, &b.CreatedAt)
+}
+
+// GetSendRestrictions returns everything that may stop a user from posting in
+// a room. Rooms of trashed topics are reported as not found.
+func (r *Repository) GetSendRestrictions(ctx context.Context, roomID int, userID int32, username string) (domain.SendRestrictions, error) {
+	var s domain.SendRestrictions
+	err := r.db.QueryRow(ctx,
+		`SELECT
+			EXISTS (SELECT 1 FROM backend_schema.chat_bans WHERE room_id = $1 AND user_id = $2),
+			(SELECT MAX(until) FROM backend_schema.chat_mutes
+			 WHERE user_id = $2 AND (room_id IS NULL OR room_id = $1) AND until > now()),
+			r.slow_mode_seconds,
+			(SELECT MAX(timestamp) FROM backend_schema.chat_messages WHERE room_id = $1 AND username = $3)
+		 FROM backend_schema.chat_rooms r WHERE r.id = $1 AND `+openRoom,
+		roomID, userID, username).Scan(&s.Banned, &s.MutedUntil, &s.SlowMode, &s.LastMessageAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.SendRestrictions{}, domain.ErrRoomNotFound
+	}
+	return s, err
+}
+
+func (r *Repository) IsBanned(ctx context.Context, roomID int, userID int32) (bool, error) {
+	var banned bool
+	err := r.db.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM backend_schema.chat_bans WHERE room_id = $1 AND user_id = $2)`,
+		roomID, userID).Scan(&banned)
+	return banned, err
+}
+
+func (r *Repository) AddMute(ctx context.Context, m domain.Mute) (domain.Mute, error) {
+	err := scanMute(r.db.QueryRow(ctx,
+		`INSERT INTO backend_schema.chat_mutes (room_id, user_id, username, until, reason, created_by)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 RETURNING `+muteColumns,
+		m.RoomID, m.UserID, m.Username, m.Until, m.Reason, m.CreatedBy), &m)
+	return m, err
+}
+
+// RemoveMutes lifts a user's active mutes in a room, or the ones covering
+// every room when roomID is nil, and returns how many were lifted.
+func (r *Repository) RemoveMutes(ctx context.Context, roomID *int, userID int32) (int64, error) {
+	tag, err := r.db.Exec(ctx,
+		`DELETE FROM backend_schema.chat_mutes
+		 WHERE user_id = $1 AND room_id IS NOT DISTINCT FROM $2 AND until > now()`,
+		userID, roomID)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *Repository) AddBan(ctx context.Context, b domain.Ban) (domain.Ban, error) {
+	err := scanBan(r.db.QueryRow(ctx,
+		`INSERT INTO backend_schema.chat_bans (room_id, user_id, username, reason, created_by)
+		 VALUES ($1, $2, $3, $4, $5)
+		 ON CONFLICT (room_id, user_id) DO UPDATE
+		 SET reason = EXCLUDED.reason, created_by = EXCLUDED.created_by, created_at = now()
+		 RETURNING `+banColumns,
+		b.RoomID, b.UserID, b.Username, b.Reason, b.CreatedBy), &b)
+	return b, err
+}
+
+func (r *Repository) RemoveBan(ctx context.Context, roomID int, userID int32) (int64, error) {
+	tag, err := r.db.Exec(ctx,
+		`DELETE FROM backend_schema.chat_bans WHERE room_id = $1 AND user_id = $2`, roomID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// GetModeration returns active mutes and bans, limited to one room and the
+// global mutes when roomID is set.
+func (r *Repository) GetModeration(ctx context.Context, roomID *int) (domain.Moderation, error) {
+	var mod domain.Moderation
+
+	rows, err := r.db.Query(ctx,
+		`SELECT `+muteColumns+` FROM backend_schema.chat_mutes
+		 WHERE until > now() AND ($1::int IS NULL OR room_id IS NULL OR room_id = $1)
+		 ORDER BY until DESC`, roomID)
+	if err != nil {
+		return mod, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m domain.Mute
+		if err := scanMute(rows, &m); err != nil {
+			return mod, err
+		}
+		mod.Mutes = append(mod.Mutes, m)
+	}
+	if err := rows.Err(); err != nil {
+		return mod, err
+	}
+
+	rows, err = r.db.Query(ctx,
+		`SELECT `+banColumns+` FROM backend_schema.chat_bans
+		 WHERE $1::int IS NULL OR room_id = $1
+		 ORDER BY created_at DESC`, roomID)
+	if err != nil {
+		return mod, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var b domain.Ban
+		if err := scanBan(rows, &b); err != nil {
+			return mod, err
+		}
+		mod.Bans = append(mod.Bans, b)
+	}
+	return mod, rows.Err()
+}
@@ -0,0 +1,133 @@
+package chat
+
+import (
+	"context"
+	"time"
+
+	domain "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/chat"
+	"github.com/jackc/pgx/v5"
+)
+
+// AddPresence records one more connection of a user to a room on an instance
+// and returns the user's connection count in the room across all instances.
+func (r *Repository) AddPresence(ctx context.Context, instanceID string, p domain.Presence) (int, error) {
+	var total int
+	err := r.db.QueryRow(ctx,
+		`WITH up AS (
+			INSERT INTO backend_schema.chat_presence (instance_id, room_id, user_id, username, connections)
+			VALUES ($1, $2, $3, $4, 1)
+			ON CONFLICT (instance_id, room_id, user_id)
+			DO UPDATE SET connections = chat_presence.connections + 1, seen_at = now()
+			RETURNING connections
+		)
+		SELECT (SELECT connections FROM up) + COALESCE(SUM(connections), 0)
+		FROM backend_schema.chat_presence
+		WHERE room_id = $2 AND user_id = $3 AND instance_id <> $1`,
+		instanceID, p.RoomID, p.UserID, p.Username).Scan(&total)
+	return total, err
+}
+
+// RemovePresence drops one connection of a user to a room on an instance and
+// returns how many connections the user still has in the room.
+func (r *Repository) RemovePresence(ctx context.Context, instanceID string, p domain.Presence) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx,
+		`UPDATE backend_schema.chat_presence SET connections = connections - 1
+		 WHERE instance_id = $1 AND room_id = $2 AND user_id = $3`,
+		instanceID, p.RoomID, p.UserID)
+	if err != nil {
+		return 0, err
+	}
+	_, err = tx.Exec(ctx,
+		`DELETE FROM backend_schema.chat_presence
+		 WHERE instance_id = $1 AND room_id = $2 AND user_id = $3 AND connections <= 0`,
+		instanceID, p.RoomID, p.UserID)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int
+	err = tx.QueryRow(ctx,
+		`SELECT COALESCE(SUM(connections), 0) FROM backend_schema.chat_presence WHERE room_id = $1 AND user_id = $2`,
+		p.RoomID, p.UserID).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+	return total, tx.Commit(ctx)
+}
+
+// GetPresence returns the users connected to a room, ordered by username.
+func (r *Repository) GetPresence(ctx context.Context, roomID int) ([]domain.Presence, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT DISTINCT room_id, user_id, username FROM backend_schema.chat_presence
+		 WHERE room_id = $1 ORDER BY username`, roomID)
+	if err != nil {
+		return nil, err
+	}
+	return collectPresence(rows)
+}
+
+// TouchPresence marks every row of an instance as still alive.
+func (r *Repository) TouchPresence(ctx context.Context, instanceID string) error {
+	_, err := r.db.Exec(ctx, `UPDATE backend_schema.chat_presence SET seen_at = now() WHERE instance_id = $1`, instanceID)
+	return err
+}
+
+// PurgePresence removes rows that have not been refreshed within staleAfter,
+// left behind by instances that stopped without cleaning up. It returns the
+// users that are no longer connected to a room anywhere.
+func (r *Repository) PurgePresence(ctx context.Context, staleAfter time.Duration) ([]domain.Presence, error) {
+	rows, err := r.db.Query(ctx,
+		`WITH gone AS (
+			DELETE FROM backend_schema.chat_presence WHERE seen_at < now() - make_interval(secs => $1)
+			RETURNING room_id, user_id, username
+		)
+		SELECT DISTINCT g.room_id, g.user_id, g.username FROM gone g
+		WHERE NOT EXISTS (
+			SELECT 1 FROM backend_schema.chat_presence p
+			WHERE p.room_id = g.room_id AND p.user_id = g.user_id
+			  AND p.seen_at >= now() - make_interval(secs => $1)
+		)`, staleAfter.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	return collectPresence(rows)
+}
+
+// ClearPresence removes every row of an instance and returns the users that
+// are no longer connected to a room anywhere.
+func (r *Repository) ClearPresence(ctx context.Context, instanceID string) ([]domain.Presence, error) {
+	rows, err := r.db.Query(ctx,
+		`WITH gone AS (
+			DELETE FROM backend_schema.chat_presence WHERE instance_id = $1
+			RETURNING room_id, user_id, username
+		)
+		SELECT DISTINCT g.room_id, g.user_id, g.username FROM gone g
+		WHERE NOT EXISTS (
+			SELECT 1 FROM backend_schema.chat_presence p
+			WHERE p.room_id = g.room_id AND p.user_id = g.user_id AND p.instance_id <> $1
+		)`, instanceID)
+	if err != nil {
+		return nil, err
+	}
+	return collectPresence(rows)
+}
+
+func collectPresence(rows pgx.Rows) ([]domain.Presence, error) {
+	defer rows.Close()
+
+	var users []domain.Presence
+	for rows.Next() {
+		var p domain.Presence
+		if err := rows.Scan(&p.RoomID, &p.UserID, &p.Username); err != nil {
+			return nil, err
+		}
+		users = append(users, p)
+	}
+	return users, rows.Err()
+}
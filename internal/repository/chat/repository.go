@@ -2,9 +2,11 @@ package chat
 
 import (
 	"context"
-	"time"
+	"errors"
+	"slices"
 
 	domain "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/chat"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 )
@@ -18,21 +20,41 @@ func New(db *pgxpool.Pool, logger *zap.Logger) *Repository {
 	return &Repository{db: db, logger: logger}
 }
 
-func (r *Repository) SaveMessage(ctx context.Context, username, content string) error {
-	_, err := r.db.Exec(ctx,
-		`INSERT INTO backend_schema.chat_messages (username, content, timestamp) 
-		 VALUES ($1, $2, NOW())`,
-		username, content,
-	)
+// messageColumns selects from backend_schema.chat_messages without an alias.
+const messageColumns = `id, room_id, username, kind, content, attachment, timestamp, edited_at,
+	(SELECT COALESCE(jsonb_object_agg(emoji, n), '{}') FROM (SELECT emoji, count(*) AS n
+		FROM backend_schema.reactions WHERE message_id = chat_messages.id GROUP BY emoji) r)`
+
+func scanMessage(row pgx.Row, msg *domain.ChatMessage) error {
+	return row.Scan(&msg.ID, &msg.RoomID, &msg.Username, &msg.Kind, &msg.Content, &msg.Attachment, &msg.Timestamp, &msg.EditedAt, &msg.Reactions)
+}
+
+// SaveMessage stores a message; ID and Timestamp are filled in by the database.
+func (r *Repository) SaveMessage(ctx context.Context, msg domain.ChatMessage) (domain.ChatMessage, error) {
+	err := r.db.QueryRow(ctx,
+		`INSERT INTO backend_schema.chat_messages (room_id, username, kind, content, attachment, timestamp) 
+		 VALUES ($1, $2, $3, $4, $5, NOW())
+		 RETURNING id, timestamp`,
+		msg.RoomID, msg.Username, msg.Kind, msg.Content, msg.Attachment,
+	).Scan(&msg.ID, &msg.Timestamp)
 	r.logger.Info("Saved message")
-	return err
+	return msg, err
 }
 
-func (r *Repository) GetRecentMessages(ctx context.Context) ([]domain.ChatMessage, error) {
+// GetRecentMessages returns up to q.Limit messages of a room in ascending id order.
+// With SinceID it returns the oldest messages after that id; otherwise the newest
+// ones, optionally before BeforeID.
+func (r *Repository) GetRecentMessages(ctx context.Context, roomID int, q domain.HistoryQuery) ([]domain.ChatMessage, error) {
+	order := "DESC"
+	if q.SinceID > 0 {
+		order = "ASC"
+	}
 	rows, err := r.db.Query(ctx,
-		`SELECT username, content, timestamp 
+		`SELECT `+messageColumns+`
 		 FROM backend_schema.chat_messages 
-		 ORDER BY timestamp ASC`)
+		 WHERE room_id = $1 AND deleted_at IS NULL AND ($2 = 0 OR id > $2) AND ($3 = 0 OR id < $3)
+		 ORDER BY id `+order+`
+		 LIMIT $4`, roomID, q.SinceID, q.BeforeID, q.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -41,21 +63,97 @@ func (r *Repository) GetRecentMessages(ctx context.Context) ([]domain.ChatMessag
 	var messages []domain.ChatMessage
 	for rows.Next() {
 		var msg domain.ChatMessage
-		if err := rows.Scan(&msg.Username, &msg.Content, &msg.Timestamp); err != nil {
+		if err := scanMessage(rows, &msg); err != nil {
 			return nil, err
 		}
 		messages = append(messages, msg)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if order == "DESC" {
+		slices.Reverse(messages)
+	}
 	r.logger.Info("Message return")
 	return messages, nil
 }
 
-func (r *Repository) DeleteOldMessages(ctx context.Context, olderThan time.Duration) error {
-	_, err := r.db.Exec(ctx,
-		`DELETE FROM backend_schema.chat_messages 
-		 WHERE timestamp < NOW() - ($1)::interval`,
-		olderThan.String(),
-	)
-	r.logger.Info("Old Message deleted")
-	return err
+// LatestMessageID returns the highest message id of a room, or 0 when it is empty.
+func (r *Repository) LatestMessageID(ctx context.Context, roomID int) (int, error) {
+	var id int
+	err := r.db.QueryRow(ctx,
+		`SELECT COALESCE(MAX(id), 0) FROM backend_schema.chat_messages WHERE room_id = $1`, roomID).Scan(&id)
+	return id, err
+}
+
+// GetMessage returns a message that has not been deleted.
+func (r *Repository) GetMessage(ctx context.Context, messageID int) (domain.ChatMessage, error) {
+	var msg domain.ChatMessage
+	err := scanMessage(r.db.QueryRow(ctx,
+		`SELECT `+messageColumns+` FROM backend_schema.chat_messages WHERE id = $1 AND deleted_at IS NULL`,
+		messageID), &msg)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ChatMessage{}, domain.ErrMessageNotFound
+	}
+	return msg, err
+}
+
+func (r *Repository) EditMessage(ctx context.Context, messageID int, content string) (domain.ChatMessage, error) {
+	var msg domain.ChatMessage
+	err := scanMessage(r.db.QueryRow(ctx,
+		`UPDATE backend_schema.chat_messages SET content = $2, edited_at = now()
+		 WHERE id = $1 AND deleted_at IS NULL
+		 RETURNING `+messageColumns,
+		messageID, content), &msg)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ChatMessage{}, domain.ErrMessageNotFound
+	}
+	return msg, err
+}
+
+// DeleteMessage hides a message from history. The row is kept with the time
+// and the user who deleted it.
+func (r *Repository) DeleteMessage(ctx context.Context, messageID int, deletedBy string) error {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE backend_schema.chat_messages SET deleted_at = now(), deleted_by = $2
+		 WHERE id = $1 AND deleted_at IS NULL`,
+		messageID, deletedBy)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrMessageNotFound
+	}
+	return nil
+}
+
+// PurgeMessages applies a retention policy to a room and returns how many
+// messages were removed. With p.Archive the messages are copied to the
+// archive table in the same statement.
+func (r *Repository) PurgeMessages(ctx context.Context, roomID int, p domain.RetentionPolicy) (int64, error) {
+	if p.MaxAge <= 0 && p.MaxMessages <= 0 {
+		return 0, nil
+	}
+
+	purge := `DELETE FROM backend_schema.chat_messages
+		 WHERE room_id = $1 AND (
+			($2::int > 0 AND timestamp < now() - make_interval(secs => $2::int))
+			OR ($3::int > 0 AND id <= (
+				SELECT id FROM backend_schema.chat_messages WHERE room_id = $1
+				ORDER BY id DESC OFFSET $3 LIMIT 1))
+		 )`
+	if p.Archive {
+		purge = `WITH gone AS (` + purge + `
+			RETURNING id, room_id, username, kind, content, attachment, timestamp, edited_at, deleted_at, deleted_by
+		)
+		INSERT INTO backend_schema.chat_messages_archive
+			(id, room_id, username, kind, content, attachment, timestamp, edited_at, deleted_at, deleted_by)
+		SELECT * FROM gone`
+	}
+
+	tag, err := r.db.Exec(ctx, purge, roomID, int(p.MaxAge.Seconds()), p.MaxMessages)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
 }
@@ -0,0 +1,135 @@
+package chat
+
+import (
+	"context"
+	"errors"
+
+	domain "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/chat"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+const roomColumns = `id, name, title, topic_id, created_by, created_at, slow_mode_seconds,
+	retention_max_age_seconds, retention_max_messages, retention_archive`
+
+// openRoom matches rooms aliased as r that are not tied to a topic in the
+// trash. Rooms of trashed topics come back when the topic is restored.
+const openRoom = `(r.topic_id IS NULL OR EXISTS (SELECT 1 FROM backend_schema.topics t
+	WHERE t.id = r.topic_id AND t.deleted_at IS NULL))`
+
+func scanRoom(row pgx.Row, room *domain.Room) error {
+	return row.Scan(&room.ID, &room.Name, &room.Title, &room.TopicID, &room.CreatedBy, &room.CreatedAt, &room.SlowMode,
+		&room.Retention.MaxAgeSeconds, &room.Retention.MaxMessages, &room.Retention.Archive)
+}
+
+func (r *Repository) GetRoomByName(ctx context.Context, name string) (domain.Room, error) {
+	var room domain.Room
+	err := scanRoom(r.db.QueryRow(ctx,
+		`SELECT `+roomColumns+` FROM backend_schema.chat_rooms r WHERE name = $1 AND `+openRoom, name), &room)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Room{}, domain.ErrRoomNotFound
+	}
+	return room, err
+}
+
+// EnsureTopicRoom returns the room of a topic, creating it on first use.
+func (r *Repository) EnsureTopicRoom(ctx context.Context, topicID int) (domain.Room, error) {
+	var room domain.Room
+	err := scanRoom(r.db.QueryRow(ctx,
+		`INSERT INTO backend_schema.chat_rooms (name, title, topic_id)
+		 SELECT 'topic-' || id, title, id FROM backend_schema.topics WHERE id = $1 AND deleted_at IS NULL
+		 ON CONFLICT (topic_id) DO UPDATE SET title = EXCLUDED.title
+		 RETURNING `+roomColumns, topicID), &room)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Room{}, domain.ErrRoomNotFound
+	}
+	return room, err
+}
+
+func (r *Repository) CreateRoom(ctx context.Context, name, title, createdBy string) (domain.Room, error) {
+	var room domain.Room
+	err := scanRoom(r.db.QueryRow(ctx,
+		`INSERT INTO backend_schema.chat_rooms (name, title, created_by) VALUES ($1, $2, $3)
+		 RETURNING `+roomColumns, name, title, createdBy), &room)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return domain.Room{}, domain.ErrRoomExists
+	}
+	if err == nil {
+		r.logger.Info("Chat room created", zap.String("room", name))
+	}
+	return room, err
+}
+
+func (r *Repository) SetSlowMode(ctx context.Context, roomID, seconds int) (domain.Room, error) {
+	var room domain.Room
+	err := scanRoom(r.db.QueryRow(ctx,
+		`UPDATE backend_schema.chat_rooms SET slow_mode_seconds = $2 WHERE id = $1
+		 RETURNING `+roomColumns, roomID, seconds), &room)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Room{}, domain.ErrRoomNotFound
+	}
+	return room, err
+}
+
+// SetRetention replaces a room's retention overrides.
+func (r *Repository) SetRetention(ctx context.Context, roomID int, ret domain.Retention) (domain.Room, error) {
+	var room domain.Room
+	err := scanRoom(r.db.QueryRow(ctx,
+		`UPDATE backend_schema.chat_rooms
+		 SET retention_max_age_seconds = $2, retention_max_messages = $3, retention_archive = $4
+		 WHERE id = $1
+		 RETURNING `+roomColumns, roomID, ret.MaxAgeSeconds, ret.MaxMessages, ret.Archive), &room)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Room{}, domain.ErrRoomNotFound
+	}
+	return room, err
+}
+
+func (r *Repository) ListRooms(ctx context.Context) ([]domain.Room, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+roomColumns+` FROM backend_schema.chat_rooms r WHERE `+openRoom+` ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rooms []domain.Room
+	for rows.Next() {
+		var room domain.Room
+		if err := scanRoom(rows, &room); err != nil {
+			return nil, err
+		}
+		rooms = append(rooms, room)
+	}
+	return rooms, rows.Err()
+}
+
+// JoinRoom records that a user has connected to a room.
+func (r *Repository) JoinRoom(ctx context.Context, roomID int, userID int32, username string) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO backend_schema.chat_room_members (room_id, user_id, username) VALUES ($1, $2, $3)
+		 ON CONFLICT (room_id, user_id) DO UPDATE SET username = EXCLUDED.username, last_seen_at = now()`,
+		roomID, userID, username)
+	return err
+}
+
+func (r *Repository) GetMembers(ctx context.Context, roomID int) ([]domain.Member, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT room_id, user_id, username, joined_at, last_seen_at
+		 FROM backend_schema.chat_room_members WHERE room_id = $1 ORDER BY last_seen_at DESC`, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []domain.Member
+	for rows.Next() {
+		var m domain.Member
+		if err := rows.Scan(&m.RoomID, &m.UserID, &m.Username, &m.JoinedAt, &m.LastSeenAt); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
@@ -2,10 +2,15 @@ package comment
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	models "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/comment"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/trash"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/pagination"
 	"go.uber.org/zap"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -18,8 +23,140 @@ func New(db *pgxpool.Pool, logger *zap.Logger) *Repository {
 	return &Repository{db: db, logger: logger}
 }
 
-func (r *Repository) GetByPostID(ctx context.Context, postID int) ([]models.Comment, error) {
-	rows, err := r.db.Query(ctx, `SELECT id, post_id, username, content, timestamp FROM backend_schema.comments WHERE post_id=$1 ORDER BY timestamp`, postID)
+// commentColumns selects from backend_schema.comments aliased as c. Only a
+// comment that is not deleted counts as the accepted answer.
+const commentColumns = `c.id, c.post_id, c.parent_id, c.depth, c.username, c.content, c.is_deleted, c.timestamp,
+	(SELECT COALESCE(jsonb_object_agg(emoji, n), '{}') FROM (SELECT emoji, count(*) AS n
+		FROM backend_schema.reactions WHERE comment_id = c.id GROUP BY emoji) r),
+	NOT c.is_deleted AND EXISTS (SELECT 1 FROM backend_schema.posts WHERE id = c.post_id AND accepted_comment_id = c.id)`
+
+// visible matches the comments shown in a post: live ones and the
+// placeholders of deleted comments that still have visible replies.
+const visible = `(c.deleted_at IS NULL OR c.is_deleted)`
+
+func scanComment(row pgx.Row, c *models.Comment) error {
+	if err := row.Scan(&c.ID, &c.PostID, &c.ParentID, &c.Depth, &c.Username, &c.Content, &c.Deleted, &c.Timestamp, &c.Reactions, &c.Accepted); err != nil {
+		return err
+	}
+	if c.Deleted {
+		c.Username = ""
+		c.Content = models.DeletedPlaceholder
+	}
+	return nil
+}
+
+// GetByPostID returns a page of a post's comments, oldest first. The accepted
+// answer is left out of the pages and put at the top of the first one instead.
+func (r *Repository) GetByPostID(ctx context.Context, postID int, page pagination.Page) ([]models.Comment, string, error) {
+	var afterTS *time.Time
+	var afterID int
+	if page.After != nil {
+		afterTS, afterID = &page.After.Timestamp, page.After.ID
+	}
+
+	rows, err := r.db.Query(ctx, `SELECT `+commentColumns+` FROM backend_schema.comments c
+		WHERE c.post_id = $1 AND `+visible+` AND ($2::timestamp IS NULL OR (c.timestamp, c.id) > ($2, $3))
+		  AND (c.is_deleted OR c.id IS DISTINCT FROM (SELECT accepted_comment_id FROM backend_schema.posts WHERE id = $1))
+		ORDER BY c.timestamp, c.id LIMIT $4`,
+		postID, afterTS, afterID, page.Limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var comments []models.Comment
+	for rows.Next() {
+		var c models.Comment
+		if err := scanComment(rows, &c); err != nil {
+			r.logger.Error("error", zap.Error(err))
+			return nil, "", err
+		}
+		comments = append(comments, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	comments, next := pagination.Trim(comments, page.Limit, func(c models.Comment) pagination.Cursor {
+		return pagination.Cursor{Timestamp: c.Timestamp, ID: c.ID}
+	})
+
+	if page.After == nil {
+		var accepted models.Comment
+		err := scanComment(r.db.QueryRow(ctx, `SELECT `+commentColumns+` FROM backend_schema.comments c
+			JOIN backend_schema.posts p ON p.accepted_comment_id = c.id
+			WHERE p.id = $1 AND c.deleted_at IS NULL AND NOT c.is_deleted`, postID), &accepted)
+		switch {
+		case err == nil:
+			comments = append([]models.Comment{accepted}, comments...)
+		case !errors.Is(err, pgx.ErrNoRows):
+			return nil, "", err
+		}
+	}
+	r.logger.Info("Post by ID return")
+	return comments, next, nil
+}
+
+// GetThreadByPostID returns a page of a post's top-level comments, oldest
+// first, each followed by all of its replies. Comments are ordered by thread
+// path, so each reply directly follows its parent and earlier siblings.
+func (r *Repository) GetThreadByPostID(ctx context.Context, postID int, page pagination.Page) ([]models.Comment, string, error) {
+	var afterTS *time.Time
+	var afterID int
+	if page.After != nil {
+		afterTS, afterID = &page.After.Timestamp, page.After.ID
+	}
+
+	rows, err := r.db.Query(ctx, `SELECT c.id, c.timestamp FROM backend_schema.comments c
+		WHERE c.post_id = $1 AND c.parent_id IS NULL AND `+visible+` AND ($2::timestamp IS NULL OR (c.timestamp, c.id) > ($2, $3))
+		ORDER BY c.timestamp, c.id LIMIT $4`,
+		postID, afterTS, afterID, page.Limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	var roots []pagination.Cursor
+	for rows.Next() {
+		var root pagination.Cursor
+		if err := rows.Scan(&root.ID, &root.Timestamp); err != nil {
+			rows.Close()
+			return nil, "", err
+		}
+		roots = append(roots, root)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	roots, next := pagination.Trim(roots, page.Limit, func(c pagination.Cursor) pagination.Cursor { return c })
+	if len(roots) == 0 {
+		return nil, "", nil
+	}
+
+	ids := make([]int, len(roots))
+	for i, root := range roots {
+		ids[i] = root.ID
+	}
+	comments, err := r.thread(ctx, ids)
+	if err != nil {
+		return nil, "", err
+	}
+	r.logger.Info("Comment thread return", zap.Int("postID", postID), zap.Int("roots", len(roots)))
+	return comments, next, nil
+}
+
+// thread returns the given top-level comments in that order, each followed
+// by its visible replies in thread path order.
+func (r *Repository) thread(ctx context.Context, rootIDs []int) ([]models.Comment, error) {
+	rows, err := r.db.Query(ctx, `WITH RECURSIVE thread AS (
+			SELECT c.id, c.post_id, c.parent_id, c.depth, c.username, c.content, c.is_deleted, c.timestamp, ARRAY[c.id] AS path
+			FROM backend_schema.comments c
+			WHERE c.id = ANY($1::int[])
+			UNION ALL
+			SELECT c.id, c.post_id, c.parent_id, c.depth, c.username, c.content, c.is_deleted, c.timestamp, t.path || c.id
+			FROM backend_schema.comments c
+			JOIN thread t ON c.parent_id = t.id
+			WHERE `+visible+`
+		)
+		SELECT `+commentColumns+` FROM thread c ORDER BY array_position($1::int[], c.path[1]), c.path`, rootIDs)
 	if err != nil {
 		return nil, err
 	}
@@ -28,22 +165,143 @@ func (r *Repository) GetByPostID(ctx context.Context, postID int) ([]models.Comm
 	var comments []models.Comment
 	for rows.Next() {
 		var c models.Comment
-		if err := rows.Scan(&c.ID, &c.PostID, &c.Username, &c.Content, &c.Timestamp); err != nil {
-			r.logger.Fatal("error", zap.Error(err))
+		if err := scanComment(rows, &c); err != nil {
 			return nil, err
 		}
 		comments = append(comments, c)
 	}
-	r.logger.Info("Post by ID return")
-	return comments, nil
+	return comments, rows.Err()
+}
+
+func (r *Repository) GetByID(ctx context.Context, commentID int) (models.Comment, error) {
+	var c models.Comment
+	err := scanComment(r.db.QueryRow(ctx, `SELECT `+commentColumns+` FROM backend_schema.comments c
+		WHERE c.id = $1 AND `+visible, commentID), &c)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Comment{}, models.ErrNotFound
+	}
+	return c, err
+}
+
+// GetAfterID returns up to limit live comments with an id greater than afterID, oldest first.
+func (r *Repository) GetAfterID(ctx context.Context, afterID, limit int) ([]models.Comment, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+commentColumns+` FROM backend_schema.comments c
+		WHERE c.id > $1 AND c.deleted_at IS NULL AND NOT c.is_deleted ORDER BY c.id LIMIT $2`, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []models.Comment
+	for rows.Next() {
+		var c models.Comment
+		if err := scanComment(rows, &c); err != nil {
+			return nil, err
+		}
+		comments = append(comments, c)
+	}
+	return comments, rows.Err()
+}
+
+// LatestID returns the highest comment id, or 0 when there are no comments.
+func (r *Repository) LatestID(ctx context.Context) (int, error) {
+	var id int
+	err := r.db.QueryRow(ctx, `SELECT COALESCE(MAX(id), 0) FROM backend_schema.comments`).Scan(&id)
+	return id, err
 }
 
-func (r *Repository) Create(ctx context.Context, postID int, username, content string) error {
-	_, err := r.db.Exec(ctx, `INSERT INTO backend_schema.comments (post_id, username, content) VALUES ($1, $2, $3)`, postID, username, content)
-	return err
+func (r *Repository) Create(ctx context.Context, postID int, parentID *int, username, content string) (models.Comment, error) {
+	var c models.Comment
+	err := scanComment(r.db.QueryRow(ctx, `INSERT INTO backend_schema.comments AS c (post_id, parent_id, depth, username, content)
+		VALUES ($1, $2, COALESCE((SELECT depth + 1 FROM backend_schema.comments WHERE id = $2), 0), $3, $4)
+		RETURNING `+commentColumns,
+		postID, parentID, username, content), &c)
+	return c, err
 }
 
-func (r *Repository) Delete(ctx context.Context, commentID int) error {
-	_, err := r.db.Exec(ctx, `DELETE FROM backend_schema.comments WHERE id=$1`, commentID)
-	return err
+// Delete moves a comment to the trash. A comment that still has visible
+// replies stays in the thread as a placeholder; placeholders left without
+// visible replies are hidden along with it.
+func (r *Repository) Delete(ctx context.Context, commentID int, deletedBy string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var hasReplies bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM backend_schema.comments c WHERE c.parent_id = $1 AND `+visible+`)`,
+		commentID).Scan(&hasReplies)
+	if err != nil {
+		return err
+	}
+	var parentID *int
+	err = tx.QueryRow(ctx, `UPDATE backend_schema.comments SET is_deleted = $2, deleted_at = now(), deleted_by = $3
+		WHERE id = $1 AND deleted_at IS NULL AND NOT is_deleted RETURNING parent_id`,
+		commentID, hasReplies, deletedBy).Scan(&parentID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	for !hasReplies && parentID != nil {
+		id := *parentID
+		parentID = nil
+		err := tx.QueryRow(ctx, `UPDATE backend_schema.comments c
+			SET is_deleted = FALSE, deleted_at = COALESCE(c.deleted_at, now()), deleted_by = COALESCE(c.deleted_by, $2)
+			WHERE c.id = $1 AND c.is_deleted AND NOT EXISTS (
+				SELECT 1 FROM backend_schema.comments r WHERE r.parent_id = c.id AND (r.deleted_at IS NULL OR r.is_deleted))
+			RETURNING c.parent_id`, id, deletedBy).Scan(&parentID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// Restore brings a comment back from the trash. Its hidden ancestors are
+// shown as placeholders again so that it has a place in the thread. Comments
+// of a post in the trash are restored with the post.
+func (r *Repository) Restore(ctx context.Context, commentID int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var postDeleted bool
+	err = tx.QueryRow(ctx, `SELECT p.deleted_at IS NOT NULL
+		FROM backend_schema.comments c JOIN backend_schema.posts p ON p.id = c.post_id
+		WHERE c.id = $1 AND c.deleted_at IS NOT NULL FOR UPDATE OF c`, commentID).Scan(&postDeleted)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return trash.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if postDeleted {
+		return trash.ErrParentDeleted
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE backend_schema.comments SET is_deleted = FALSE, deleted_at = NULL, deleted_by = NULL
+		WHERE id = $1`, commentID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `WITH RECURSIVE ancestors AS (
+			SELECT parent_id AS id FROM backend_schema.comments WHERE id = $1
+			UNION ALL
+			SELECT c.parent_id FROM backend_schema.comments c JOIN ancestors a ON c.id = a.id
+		)
+		UPDATE backend_schema.comments SET is_deleted = TRUE
+		WHERE id IN (SELECT id FROM ancestors) AND deleted_at IS NOT NULL`, commentID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
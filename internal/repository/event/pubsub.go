@@ -0,0 +1,95 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/event"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// channel is the Postgres NOTIFY channel shared by all instances.
+const channel = "forum_events"
+
+// maxPayload is just under the 8000 byte limit Postgres puts on NOTIFY payloads.
+const maxPayload = 7900
+
+var ErrPayloadTooLarge = errors.New("event payload too large for NOTIFY")
+
+// PubSub fans events out across server instances with Postgres LISTEN/NOTIFY.
+type PubSub struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func New(db *pgxpool.Pool, logger *zap.Logger) *PubSub {
+	return &PubSub{db: db, logger: logger}
+}
+
+func (p *PubSub) Publish(ctx context.Context, e event.Event) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(e); err != nil {
+		return err
+	}
+	if buf.Len() > maxPayload {
+		return ErrPayloadTooLarge
+	}
+
+	_, err := p.db.Exec(ctx, `SELECT pg_notify($1, $2)`, channel, buf.String())
+	return err
+}
+
+// Listen delivers every published event, including this instance's own, to
+// handle until ctx is cancelled. Lost connections are re-established.
+func (p *PubSub) Listen(ctx context.Context, handle func(event.Event)) {
+	backoff := time.Second
+	for ctx.Err() == nil {
+		err := p.listen(ctx, handle)
+		if ctx.Err() != nil {
+			return
+		}
+		p.logger.Error("Event listener disconnected", zap.Error(err), zap.Duration("retryIn", backoff))
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		backoff = min(backoff*2, 30*time.Second)
+	}
+}
+
+func (p *PubSub) listen(ctx context.Context, handle func(event.Event)) error {
+	poolConn, err := p.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// A listening connection must not go back to the pool.
+	conn := poolConn.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, `LISTEN `+channel); err != nil {
+		return err
+	}
+	p.logger.Info("Listening for events", zap.String("channel", channel))
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var e event.Event
+		if err := json.Unmarshal([]byte(n.Payload), &e); err != nil {
+			p.logger.Error("Failed to decode event", zap.Error(err))
+			continue
+		}
+		handle(e)
+	}
+}
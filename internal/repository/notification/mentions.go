@@ -0,0 +1,67 @@
+package notification
+
+import (
+	"context"
+	"time"
+
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/notification"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/pagination"
+	"github.com/jackc/pgx/v5"
+)
+
+const mentionColumns = `id, user_id, username, actor, post_id, comment_id, room_id, message_id, excerpt, timestamp`
+
+func scanMention(row pgx.Row, m *notification.Mention) error {
+	return row.Scan(&m.ID, &m.UserID, &m.Username, &m.Actor, &m.PostID, &m.CommentID, &m.RoomID, &m.MessageID, &m.Excerpt, &m.Timestamp)
+}
+
+// AddMentions stores m once for every mentioned user, given as username -> user id.
+func (r *Repository) AddMentions(ctx context.Context, m notification.Mention, users map[string]int32) error {
+	usernames := make([]string, 0, len(users))
+	userIDs := make([]int32, 0, len(users))
+	for username, id := range users {
+		usernames = append(usernames, username)
+		userIDs = append(userIDs, id)
+	}
+
+	_, err := r.db.Exec(ctx, `INSERT INTO backend_schema.mentions
+			(user_id, username, actor, post_id, comment_id, room_id, message_id, excerpt)
+		SELECT user_id, username, $3, $4, $5, $6, $7, $8
+		FROM unnest($1::int[], $2::text[]) AS u(user_id, username)`,
+		userIDs, usernames, m.Actor, m.PostID, m.CommentID, m.RoomID, m.MessageID, m.Excerpt)
+	return err
+}
+
+// GetMentions returns the mentions of a user, newest first.
+func (r *Repository) GetMentions(ctx context.Context, userID int32, page pagination.Page) ([]notification.Mention, string, error) {
+	var afterTS *time.Time
+	var afterID int
+	if page.After != nil {
+		afterTS, afterID = &page.After.Timestamp, page.After.ID
+	}
+
+	rows, err := r.db.Query(ctx, `SELECT `+mentionColumns+` FROM backend_schema.mentions
+		WHERE user_id = $1 AND ($2::timestamptz IS NULL OR (timestamp, id) < ($2, $3))
+		ORDER BY timestamp DESC, id DESC LIMIT $4`,
+		userID, afterTS, afterID, page.Limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var mentions []notification.Mention
+	for rows.Next() {
+		var m notification.Mention
+		if err := scanMention(rows, &m); err != nil {
+			return nil, "", err
+		}
+		mentions = append(mentions, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	mentions, next := pagination.Trim(mentions, page.Limit, func(m notification.Mention) pagination.Cursor {
+		return pagination.Cursor{Timestamp: m.Timestamp, ID: m.ID}
+	})
+	return mentions, next, nil
+}
@@ -0,0 +1,126 @@
+package notification
+
+import (
+	"context"
+	"time"
+
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/notification"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/pagination"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type Repository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func New(db *pgxpool.Pool, logger *zap.Logger) *Repository {
+	return &Repository{db: db, logger: logger}
+}
+
+const notificationColumns = `id, user_id, kind, actor, post_id, comment_id, room_id, message_id, title, excerpt, read_at, timestamp`
+
+func scanNotification(row pgx.Row, n *notification.Notification) error {
+	return row.Scan(&n.ID, &n.UserID, &n.Kind, &n.Actor, &n.PostID, &n.CommentID, &n.RoomID, &n.MessageID, &n.Title, &n.Excerpt, &n.ReadAt, &n.Timestamp)
+}
+
+// Add stores a copy of n for each of userIDs and returns the stored rows.
+func (r *Repository) Add(ctx context.Context, n notification.Notification, userIDs []int32) ([]notification.Notification, error) {
+	rows, err := r.db.Query(ctx, `INSERT INTO backend_schema.notifications
+			(user_id, kind, actor, post_id, comment_id, room_id, message_id, title, excerpt)
+		SELECT user_id, $2, $3, $4, $5, $6, $7, $8, $9 FROM unnest($1::int[]) AS user_id
+		RETURNING `+notificationColumns,
+		userIDs, n.Kind, n.Actor, n.PostID, n.CommentID, n.RoomID, n.MessageID, n.Title, n.Excerpt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var created []notification.Notification
+	for rows.Next() {
+		var n notification.Notification
+		if err := scanNotification(rows, &n); err != nil {
+			return nil, err
+		}
+		created = append(created, n)
+	}
+	return created, rows.Err()
+}
+
+// GetByUser returns a user's notifications, newest first.
+func (r *Repository) GetByUser(ctx context.Context, userID int32, unreadOnly bool, page pagination.Page) ([]notification.Notification, string, error) {
+	var afterTS *time.Time
+	var afterID int
+	if page.After != nil {
+		afterTS, afterID = &page.After.Timestamp, page.After.ID
+	}
+
+	rows, err := r.db.Query(ctx, `SELECT `+notificationColumns+` FROM backend_schema.notifications
+		WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+		  AND ($3::timestamptz IS NULL OR (timestamp, id) < ($3, $4))
+		ORDER BY timestamp DESC, id DESC LIMIT $5`,
+		userID, unreadOnly, afterTS, afterID, page.Limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var list []notification.Notification
+	for rows.Next() {
+		var n notification.Notification
+		if err := scanNotification(rows, &n); err != nil {
+			return nil, "", err
+		}
+		list = append(list, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	list, next := pagination.Trim(list, page.Limit, func(n notification.Notification) pagination.Cursor {
+		return pagination.Cursor{Timestamp: n.Timestamp, ID: n.ID}
+	})
+	return list, next, nil
+}
+
+func (r *Repository) CountUnread(ctx context.Context, userID int32) (int, error) {
+	var n int
+	err := r.db.QueryRow(ctx,
+		`SELECT count(*) FROM backend_schema.notifications WHERE user_id = $1 AND read_at IS NULL`, userID).Scan(&n)
+	return n, err
+}
+
+// MarkRead marks the given notifications of a user as read, or all of them
+// when ids is empty.
+func (r *Repository) MarkRead(ctx context.Context, userID int32, ids []int) (int64, error) {
+	tag, err := r.db.Exec(ctx, `UPDATE backend_schema.notifications SET read_at = now()
+		WHERE user_id = $1 AND read_at IS NULL AND (cardinality($2::int[]) = 0 OR id = ANY($2))`,
+		userID, ids)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// UserIDs resolves usernames to user ids through the user directory. Users
+// that have never signed in are left out.
+func (r *Repository) UserIDs(ctx context.Context, usernames []string) (map[string]int32, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT username, id FROM backend_schema.users WHERE username = ANY($1)`, usernames)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[string]int32)
+	for rows.Next() {
+		var username string
+		var id int32
+		if err := rows.Scan(&username, &id); err != nil {
+			return nil, err
+		}
+		ids[username] = id
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,76 @@
+package notification
+
+import (
+	"context"
+	"errors"
+
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/notification"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Subscribe makes a user follow a topic or watch a post. Subscribing twice is a no-op.
+func (r *Repository) Subscribe(ctx context.Context, userID int32, topicID, postID *int) error {
+	_, err := r.db.Exec(ctx, `INSERT INTO backend_schema.subscriptions (user_id, topic_id, post_id)
+		VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`, userID, topicID, postID)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return notification.ErrTargetNotFound
+	}
+	return err
+}
+
+func (r *Repository) Unsubscribe(ctx context.Context, userID int32, topicID, postID *int) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM backend_schema.subscriptions
+		WHERE user_id = $1 AND topic_id IS NOT DISTINCT FROM $2 AND post_id IS NOT DISTINCT FROM $3`,
+		userID, topicID, postID)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// GetSubscriptions returns a user's subscriptions, newest first.
+func (r *Repository) GetSubscriptions(ctx context.Context, userID int32) ([]notification.Subscription, error) {
+	rows, err := r.db.Query(ctx, `SELECT topic_id, post_id, created_at FROM backend_schema.subscriptions
+		WHERE user_id = $1 ORDER BY created_at DESC, id DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []notification.Subscription
+	for rows.Next() {
+		var s notification.Subscription
+		if err := rows.Scan(&s.TopicID, &s.PostID, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		subs = append(subs, s)
+	}
+	return subs, rows.Err()
+}
+
+func (r *Repository) TopicSubscribers(ctx context.Context, topicID int) ([]int32, error) {
+	return r.subscribers(ctx, `SELECT user_id FROM backend_schema.subscriptions WHERE topic_id = $1`, topicID)
+}
+
+func (r *Repository) PostSubscribers(ctx context.Context, postID int) ([]int32, error) {
+	return r.subscribers(ctx, `SELECT user_id FROM backend_schema.subscriptions WHERE post_id = $1`, postID)
+}
+
+func (r *Repository) subscribers(ctx context.Context, query string, id int) ([]int32, error) {
+	rows, err := r.db.Query(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int32
+	for rows.Next() {
+		var userID int32
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		ids = append(ids, userID)
+	}
+	return ids, rows.Err()
+}
@@ -2,10 +2,15 @@ package post
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/post"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/trash"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/pagination"
 	"go.uber.org/zap"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -18,53 +23,296 @@ func New(db *pgxpool.Pool, logger *zap.Logger) *PostgresRepo {
 	return &PostgresRepo{db: db, logger: logger}
 }
 
-func (r *PostgresRepo) GetAll(ctx context.Context) ([]post.Post, error) {
-	rows, err := r.db.Query(ctx, `SELECT id, topic_id, title, content, username, timestamp FROM backend_schema.posts ORDER BY timestamp DESC`)
+// postColumns selects from backend_schema.posts aliased as p.
+const postColumns = `p.id, p.topic_id, p.title, p.content, p.username,
+	ARRAY(SELECT t.name FROM backend_schema.post_tags pt JOIN backend_schema.tags t ON t.id = pt.tag_id
+		WHERE pt.post_id = p.id ORDER BY t.name),
+	(SELECT COALESCE(jsonb_object_agg(emoji, n), '{}') FROM (SELECT emoji, count(*) AS n
+		FROM backend_schema.reactions WHERE post_id = p.id GROUP BY emoji) r),
+	` + acceptedAnswer + `, p.pinned, p.locked, p.archived, p.timestamp, p.updated_at`
+
+// acceptedAnswer is the accepted answer of the post aliased as p. It is NULL
+// while the comment is deleted or in the trash, so a restored answer counts
+// again.
+const acceptedAnswer = `(SELECT c.id FROM backend_schema.comments c
+	WHERE c.id = p.accepted_comment_id AND c.deleted_at IS NULL AND NOT c.is_deleted)`
+
+func scanPost(row pgx.Row, p *post.Post) error {
+	return row.Scan(&p.ID, &p.TopicID, &p.Title, &p.Content, &p.Username, &p.Tags, &p.Reactions, &p.AcceptedCommentID, &p.Pinned, &p.Locked, &p.Archived, &p.Timestamp, &p.UpdatedAt)
+}
+
+func postCursor(p post.Post) pagination.Cursor {
+	return pagination.Cursor{Timestamp: p.Timestamp, ID: p.ID, Pinned: p.Pinned}
+}
+
+// afterArgs turns a page cursor into query arguments; a nil timestamp disables the keyset filter.
+func afterArgs(page pagination.Page) (*time.Time, int, bool) {
+	if page.After == nil {
+		return nil, 0, false
+	}
+	return &page.After.Timestamp, page.After.ID, page.After.Pinned
+}
+
+func (r *PostgresRepo) queryPage(ctx context.Context, page pagination.Page, query string, args ...any) ([]post.Post, string, error) {
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer rows.Close()
 
 	var posts []post.Post
 	for rows.Next() {
 		var p post.Post
-		if err := rows.Scan(&p.ID, &p.TopicID, &p.Title, &p.Content, &p.Username, &p.Timestamp); err != nil {
-			return nil, err
+		if err := scanPost(rows, &p); err != nil {
+			return nil, "", err
 		}
-		r.logger.Info("Posts: %+v\n")
 		posts = append(posts, p)
 	}
-	r.logger.Info("All posts return")
-	return posts, nil
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	posts, next := pagination.Trim(posts, page.Limit, postCursor)
+	return posts, next, nil
+}
+
+// listFilter is the condition of List; $1 to $4 are the fields of post.Filter.
+const listFilter = `($1::int IS NULL OR p.topic_id = $1)
+	AND ($2 = '' OR EXISTS (SELECT 1 FROM backend_schema.post_tags pt
+		JOIN backend_schema.tags t ON t.id = pt.tag_id WHERE pt.post_id = p.id AND t.name = $2))
+	AND ($3::bool IS NULL
+		OR ($3 AND ` + acceptedAnswer + ` IS NOT NULL)
+		OR (NOT $3 AND ` + acceptedAnswer + ` IS NULL
+			AND EXISTS (SELECT 1 FROM backend_schema.topics t WHERE t.id = p.topic_id AND t.is_qa)))
+	AND p.archived = $4 AND p.deleted_at IS NULL`
+
+// List returns the posts matching f, newest first. When listing a topic its
+// pinned posts come first, newest first among themselves, and are paged like
+// the others.
+func (r *PostgresRepo) List(ctx context.Context, f post.Filter, page pagination.Page) ([]post.Post, string, error) {
+	pinFirst := f.TopicID != nil && !f.Archived
+	afterTS, afterID, afterPinned := afterArgs(page)
+	posts, next, err := r.queryPage(ctx, page, `SELECT `+postColumns+` FROM backend_schema.posts p
+		WHERE `+listFilter+`
+		  AND ($6::timestamptz IS NULL OR ($5 AND p.pinned, p.timestamp, p.id) < ($5 AND $8, $6, $7))
+		ORDER BY ($5 AND p.pinned) DESC, p.timestamp DESC, p.id DESC LIMIT $9`,
+		f.TopicID, f.Tag, f.Answered, f.Archived, pinFirst, afterTS, afterID, afterPinned, page.Limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	r.logger.Info("Posts return", zap.Intp("topicID", f.TopicID), zap.String("tag", f.Tag))
+	return posts, next, nil
+}
+
+func (r *PostgresRepo) GetByID(ctx context.Context, postID int) (post.Post, error) {
+	var p post.Post
+	err := scanPost(r.db.QueryRow(ctx, `SELECT `+postColumns+` FROM backend_schema.posts p
+		WHERE p.id = $1 AND p.deleted_at IS NULL`, postID), &p)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return post.Post{}, post.ErrNotFound
+	}
+	return p, err
+}
+
+// GetAfterID returns up to limit posts with an id greater than afterID, oldest first.
+func (r *PostgresRepo) GetAfterID(ctx context.Context, afterID, limit int) ([]post.Post, error) {
+	posts, _, err := r.queryPage(ctx, pagination.Page{Limit: limit}, `SELECT `+postColumns+` FROM backend_schema.posts p
+		WHERE p.id > $1 AND p.deleted_at IS NULL ORDER BY p.id LIMIT $2`, afterID, limit)
+	return posts, err
+}
+
+// LatestID returns the highest post id, or 0 when there are no posts.
+func (r *PostgresRepo) LatestID(ctx context.Context) (int, error) {
+	var id int
+	err := r.db.QueryRow(ctx, `SELECT COALESCE(MAX(id), 0) FROM backend_schema.posts`).Scan(&id)
+	return id, err
+}
+
+// Create stores a post with its first revision and tags and returns it with
+// id and timestamp set. Tags that do not exist yet are created.
+func (r *PostgresRepo) Create(ctx context.Context, p post.Post) (post.Post, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return post.Post{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `INSERT INTO backend_schema.posts (topic_id, title, content, username) VALUES ($1, $2, $3, $4) RETURNING id, timestamp`,
+		p.TopicID, p.Title, p.Content, p.Username).Scan(&p.ID, &p.Timestamp)
+	if err != nil {
+		return post.Post{}, err
+	}
+
+	_, err = tx.Exec(ctx, `INSERT INTO backend_schema.post_revisions (post_id, version, title, content, username) VALUES ($1, 1, $2, $3, $4)`,
+		p.ID, p.Title, p.Content, p.Username)
+	if err != nil {
+		return post.Post{}, err
+	}
+
+	if len(p.Tags) > 0 {
+		_, err = tx.Exec(ctx, `INSERT INTO backend_schema.tags (name) SELECT unnest($1::text[]) ON CONFLICT (name) DO NOTHING`, p.Tags)
+		if err != nil {
+			return post.Post{}, err
+		}
+		_, err = tx.Exec(ctx, `INSERT INTO backend_schema.post_tags (post_id, tag_id)
+			SELECT $1, id FROM backend_schema.tags WHERE name = ANY($2)`, p.ID, p.Tags)
+		if err != nil {
+			return post.Post{}, err
+		}
+	}
+	return p, tx.Commit(ctx)
+}
+
+// SetState updates the moderation state of a post and returns the post.
+func (r *PostgresRepo) SetState(ctx context.Context, postID int, s post.State) (post.Post, error) {
+	var p post.Post
+	err := scanPost(r.db.QueryRow(ctx, `UPDATE backend_schema.posts AS p
+		SET pinned = COALESCE($2, pinned), locked = COALESCE($3, locked), archived = COALESCE($4, archived)
+		WHERE p.id = $1 AND p.deleted_at IS NULL RETURNING `+postColumns, postID, s.Pinned, s.Locked, s.Archived), &p)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return post.Post{}, post.ErrNotFound
+	}
+	return p, err
+}
+
+// SetAccepted sets the accepted answer of a post; nil clears it.
+func (r *PostgresRepo) SetAccepted(ctx context.Context, postID int, commentID *int) error {
+	tag, err := r.db.Exec(ctx, `UPDATE backend_schema.posts SET accepted_comment_id = $2
+		WHERE id = $1 AND deleted_at IS NULL`, postID, commentID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return post.ErrNotFound
+	}
+	return nil
+}
+
+// Update overwrites the post's title and content and appends them as a new revision.
+func (r *PostgresRepo) Update(ctx context.Context, postID int, title, content, editor string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Locking the post first serializes concurrent edits, so each of them
+	// sees the version written by the one before.
+	var locked int
+	err = tx.QueryRow(ctx, `SELECT id FROM backend_schema.posts WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, postID).Scan(&locked)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return post.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE backend_schema.posts SET title = $2, content = $3, updated_at = NOW() WHERE id = $1`,
+		postID, title, content)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `INSERT INTO backend_schema.post_revisions (post_id, version, title, content, username)
+		SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3, $4 FROM backend_schema.post_revisions WHERE post_id = $1`,
+		postID, title, content, editor)
+	if err != nil {
+		return err
+	}
+	r.logger.Info("Post updated", zap.Int("postID", postID))
+	return tx.Commit(ctx)
 }
 
-func (r *PostgresRepo) GetByTopic(ctx context.Context, topicID int) ([]post.Post, error) {
-	rows, err := r.db.Query(ctx, `SELECT id, topic_id, title, content, username, timestamp FROM backend_schema.posts WHERE topic_id=$1 ORDER BY timestamp DESC`, topicID)
+func (r *PostgresRepo) GetRevisions(ctx context.Context, postID int) ([]post.Revision, error) {
+	rows, err := r.db.Query(ctx, `SELECT rv.id, rv.post_id, rv.version, rv.title, rv.content, rv.username, rv.timestamp
+		FROM backend_schema.post_revisions rv
+		JOIN backend_schema.posts p ON p.id = rv.post_id AND p.deleted_at IS NULL
+		WHERE rv.post_id = $1 ORDER BY rv.version`, postID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var posts []post.Post
+	var revisions []post.Revision
 	for rows.Next() {
-		var p post.Post
-		if err := rows.Scan(&p.ID, &p.TopicID, &p.Title, &p.Content, &p.Username, &p.Timestamp); err != nil {
-			r.logger.Fatal("error", zap.Error(err))
+		var rev post.Revision
+		if err := rows.Scan(&rev.ID, &rev.PostID, &rev.Version, &rev.Title, &rev.Content, &rev.Username, &rev.Timestamp); err != nil {
 			return nil, err
 		}
-		posts = append(posts, p)
+		revisions = append(revisions, rev)
 	}
-	r.logger.Info("Posts by Topic return")
-	return posts, nil
+	return revisions, rows.Err()
 }
 
-func (r *PostgresRepo) Create(ctx context.Context, p post.Post) error {
-	_, err := r.db.Exec(ctx, `INSERT INTO backend_schema.posts (topic_id, title, content, username) VALUES ($1, $2, $3, $4)`,
-		p.TopicID, p.Title, p.Content, p.Username)
-	return err
+func (r *PostgresRepo) GetRevision(ctx context.Context, postID, version int) (post.Revision, error) {
+	var rev post.Revision
+	err := r.db.QueryRow(ctx, `SELECT rv.id, rv.post_id, rv.version, rv.title, rv.content, rv.username, rv.timestamp
+		FROM backend_schema.post_revisions rv
+		JOIN backend_schema.posts p ON p.id = rv.post_id AND p.deleted_at IS NULL
+		WHERE rv.post_id = $1 AND rv.version = $2`, postID, version).
+		Scan(&rev.ID, &rev.PostID, &rev.Version, &rev.Title, &rev.Content, &rev.Username, &rev.Timestamp)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return post.Revision{}, post.ErrRevisionNotFound
+	}
+	return rev, err
 }
 
-func (r *PostgresRepo) Delete(ctx context.Context, postID int) error {
-	_, err := r.db.Exec(ctx, `DELETE FROM backend_schema.posts WHERE id = $1`, postID)
-	return err
+// Delete moves a post to the trash along with its comments.
+func (r *PostgresRepo) Delete(ctx context.Context, postID int, deletedBy string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `UPDATE backend_schema.posts SET deleted_at = now(), deleted_by = $2
+		WHERE id = $1 AND deleted_at IS NULL`, postID, deletedBy)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return post.ErrNotFound
+	}
+	_, err = tx.Exec(ctx, `UPDATE backend_schema.comments SET deleted_at = now(), deleted_by = $2
+		WHERE post_id = $1 AND deleted_at IS NULL`, postID, deletedBy)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Restore brings a post back from the trash together with the comments that
+// were deleted with it. A post whose topic is in the trash cannot be restored
+// on its own.
+func (r *PostgresRepo) Restore(ctx context.Context, postID int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var deletedAt time.Time
+	var topicDeleted bool
+	err = tx.QueryRow(ctx, `SELECT p.deleted_at, t.deleted_at IS NOT NULL
+		FROM backend_schema.posts p JOIN backend_schema.topics t ON t.id = p.topic_id
+		WHERE p.id = $1 AND p.deleted_at IS NOT NULL FOR UPDATE OF p`, postID).Scan(&deletedAt, &topicDeleted)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return trash.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if topicDeleted {
+		return trash.ErrParentDeleted
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE backend_schema.comments SET deleted_at = NULL, deleted_by = NULL
+		WHERE post_id = $1 AND deleted_at = $2`, postID, deletedAt)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `UPDATE backend_schema.posts SET deleted_at = NULL, deleted_by = NULL WHERE id = $1`, postID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
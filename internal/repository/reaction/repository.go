@@ -0,0 +1,145 @@
+package reaction
+
+import (
+	"context"
+	"errors"
+
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/reaction"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type Repository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func New(db *pgxpool.Pool, logger *zap.Logger) *Repository {
+	return &Repository{db: db, logger: logger}
+}
+
+// targetFilter matches the reactions on the target passed as $1, $2 and $3;
+// the ids left nil match nothing.
+const targetFilter = `(post_id = $1 OR comment_id = $2 OR message_id = $3)`
+
+// Toggle removes the user's reaction if it exists and adds it otherwise. It
+// reports whether the user has the reaction afterwards. Reactions on trashed
+// posts and comments, and on an archived post or its comments, cannot be
+// changed.
+func (r *Repository) Toggle(ctx context.Context, userID int32, t reaction.Target, emoji string) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	if t.PostID != nil || t.CommentID != nil {
+		var archived bool
+		err := tx.QueryRow(ctx, `SELECT archived FROM backend_schema.posts
+			WHERE id = COALESCE($1, (SELECT post_id FROM backend_schema.comments
+				WHERE id = $2 AND deleted_at IS NULL AND NOT is_deleted))
+			  AND deleted_at IS NULL
+			FOR SHARE`, t.PostID, t.CommentID).Scan(&archived)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, reaction.ErrTargetNotFound
+		}
+		if err != nil {
+			return false, err
+		}
+		if archived {
+			return false, reaction.ErrArchived
+		}
+	}
+
+	tag, err := tx.Exec(ctx, `DELETE FROM backend_schema.reactions WHERE `+targetFilter+` AND user_id = $4 AND emoji = $5`,
+		t.PostID, t.CommentID, t.MessageID, userID, emoji)
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() > 0 {
+		return false, tx.Commit(ctx)
+	}
+
+	tag, err = tx.Exec(ctx, `INSERT INTO backend_schema.reactions (post_id, comment_id, message_id, user_id, emoji)
+		VALUES ($1, $2, $3, $4, $5) ON CONFLICT DO NOTHING`,
+		t.PostID, t.CommentID, t.MessageID, userID, emoji)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return false, reaction.ErrTargetNotFound
+	}
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() > 0 {
+		return true, tx.Commit(ctx)
+	}
+
+	// A concurrent toggle added the reaction first and may have removed it
+	// again since; report what is stored now.
+	var reacted bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM backend_schema.reactions
+		WHERE `+targetFilter+` AND user_id = $4 AND emoji = $5)`,
+		t.PostID, t.CommentID, t.MessageID, userID, emoji).Scan(&reacted)
+	if err != nil {
+		return false, err
+	}
+	return reacted, tx.Commit(ctx)
+}
+
+// Counts returns how many users reacted to the target with each emoji.
+func (r *Repository) Counts(ctx context.Context, t reaction.Target) (reaction.Counts, error) {
+	rows, err := r.db.Query(ctx, `SELECT emoji, count(*) FROM backend_schema.reactions
+		WHERE `+targetFilter+` GROUP BY emoji`, t.PostID, t.CommentID, t.MessageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := reaction.Counts{}
+	for rows.Next() {
+		var emoji string
+		var n int
+		if err := rows.Scan(&emoji, &n); err != nil {
+			return nil, err
+		}
+		counts[emoji] = n
+	}
+	return counts, rows.Err()
+}
+
+// List returns who reacted to the target, grouped by emoji, most used first.
+func (r *Repository) List(ctx context.Context, t reaction.Target) ([]reaction.Reaction, error) {
+	rows, err := r.db.Query(ctx, `SELECT r.emoji, array_agg(u.username ORDER BY r.created_at, r.id)
+		FROM backend_schema.reactions r
+		JOIN backend_schema.users u ON u.id = r.user_id
+		WHERE `+targetFilter+`
+		GROUP BY r.emoji ORDER BY count(*) DESC, min(r.created_at)`, t.PostID, t.CommentID, t.MessageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reactions []reaction.Reaction
+	for rows.Next() {
+		var re reaction.Reaction
+		if err := rows.Scan(&re.Emoji, &re.Usernames); err != nil {
+			return nil, err
+		}
+		re.Count = len(re.Usernames)
+		reactions = append(reactions, re)
+	}
+	return reactions, rows.Err()
+}
+
+// MessageRoom returns the room of a chat message that has not been deleted.
+func (r *Repository) MessageRoom(ctx context.Context, messageID int) (int, error) {
+	var roomID int
+	err := r.db.QueryRow(ctx, `SELECT room_id FROM backend_schema.chat_messages
+		WHERE id = $1 AND deleted_at IS NULL`, messageID).Scan(&roomID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, reaction.ErrTargetNotFound
+	}
+	return roomID, err
+}
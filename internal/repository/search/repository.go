@@ -0,0 +1,75 @@
+package search
+
+import (
+	"context"
+
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/search"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type Repository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func New(db *pgxpool.Pool, logger *zap.Logger) *Repository {
+	return &Repository{db: db, logger: logger}
+}
+
+// headlineOptions configures the snippets returned as Result.Headline.
+const headlineOptions = `StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2`
+
+// escapedContent is m.content with HTML special characters escaped, so that
+// the only markup in a headline is the <mark> tags added by ts_headline.
+const escapedContent = `replace(replace(replace(replace(replace(m.content,
+	'&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`
+
+// Search returns matches ordered by rank, newest first among equal ranks.
+// Headlines are only built for the rows of the requested page.
+func (r *Repository) Search(ctx context.Context, q search.Query, limit, offset int) ([]search.Result, error) {
+	rows, err := r.db.Query(ctx, `WITH q AS (SELECT websearch_to_tsquery('russian', $1) AS query),
+		matches AS (
+			SELECT 'post' AS type, p.id, p.id AS post_id, p.topic_id, p.title, p.content, p.username,
+				p.timestamp, ts_rank(p.search_vector, q.query) AS rank
+			FROM backend_schema.posts p, q
+			WHERE $2 IN ('', 'post') AND p.deleted_at IS NULL AND p.search_vector @@ q.query
+			  AND ($3::int IS NULL OR p.topic_id = $3)
+			  AND ($4 = '' OR p.username = $4)
+			  AND ($5::timestamptz IS NULL OR p.timestamp >= $5)
+			  AND ($6::timestamptz IS NULL OR p.timestamp < $6)
+			UNION ALL
+			SELECT 'comment', c.id, c.post_id, p.topic_id, p.title, c.content, c.username,
+				c.timestamp::timestamptz, ts_rank(c.search_vector, q.query)
+			FROM backend_schema.comments c
+			JOIN backend_schema.posts p ON p.id = c.post_id, q
+			WHERE $2 IN ('', 'comment') AND c.deleted_at IS NULL AND NOT c.is_deleted AND c.search_vector @@ q.query
+			  AND ($3::int IS NULL OR p.topic_id = $3)
+			  AND ($4 = '' OR c.username = $4)
+			  AND ($5::timestamptz IS NULL OR c.timestamp >= $5)
+			  AND ($6::timestamptz IS NULL OR c.timestamp < $6)
+			ORDER BY rank DESC, timestamp DESC, id DESC
+			LIMIT $7 OFFSET $8
+		)
+		SELECT m.type, m.id, m.post_id, m.topic_id, m.title,
+			ts_headline('russian', `+escapedContent+`, q.query, '`+headlineOptions+`'),
+			m.username, m.timestamp, m.rank
+		FROM matches m, q
+		ORDER BY m.rank DESC, m.timestamp DESC, m.id DESC`,
+		q.Text, q.Type, q.TopicID, q.Author, q.From, q.To, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []search.Result
+	for rows.Next() {
+		var res search.Result
+		if err := rows.Scan(&res.Type, &res.ID, &res.PostID, &res.TopicID, &res.Title,
+			&res.Headline, &res.Username, &res.Timestamp, &res.Rank); err != nil {
+			return nil, err
+		}
+		results = append(results, res)
+	}
+	return results, rows.Err()
+}
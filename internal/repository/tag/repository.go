@@ -0,0 +1,92 @@
+package tag
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/tag"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type Repository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func New(db *pgxpool.Pool, logger *zap.Logger) *Repository {
+	return &Repository{db: db, logger: logger}
+}
+
+// List returns tags starting with prefix, most used first. Only posts that
+// are neither archived nor in the trash are counted.
+func (r *Repository) List(ctx context.Context, prefix string, limit int) ([]tag.Tag, error) {
+	rows, err := r.db.Query(ctx, `SELECT t.name, count(p.id) FROM backend_schema.tags t
+		LEFT JOIN backend_schema.post_tags pt ON pt.tag_id = t.id
+		LEFT JOIN backend_schema.posts p ON p.id = pt.post_id AND p.deleted_at IS NULL AND NOT p.archived
+		WHERE t.name LIKE $1 || '%'
+		GROUP BY t.id ORDER BY count(p.id) DESC, t.name LIMIT $2`,
+		escapeLike(prefix), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []tag.Tag
+	for rows.Next() {
+		var t tag.Tag
+		if err := rows.Scan(&t.Name, &t.Count); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+// Existing returns which of names are known tags.
+func (r *Repository) Existing(ctx context.Context, names []string) ([]string, error) {
+	rows, err := r.db.Query(ctx, `SELECT name FROM backend_schema.tags WHERE name = ANY($1)`, names)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var existing []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		existing = append(existing, name)
+	}
+	return existing, rows.Err()
+}
+
+func (r *Repository) Create(ctx context.Context, name string) error {
+	_, err := r.db.Exec(ctx, `INSERT INTO backend_schema.tags (name) VALUES ($1)`, name)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return tag.ErrExists
+	}
+	return err
+}
+
+// Delete removes a tag from the allowlist and from every post carrying it.
+func (r *Repository) Delete(ctx context.Context, name string) error {
+	res, err := r.db.Exec(ctx, `DELETE FROM backend_schema.tags WHERE name = $1`, name)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return tag.ErrNotFound
+	}
+	return nil
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
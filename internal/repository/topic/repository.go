@@ -2,8 +2,13 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/topic"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/trash"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/pagination"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 )
@@ -17,35 +22,133 @@ func New(db *pgxpool.Pool, logger *zap.Logger) *TopicRepository {
 	return &TopicRepository{DB: db, logger: logger}
 }
 
-func (r *TopicRepository) GetAll(ctx context.Context) ([]topic.Topic, error) {
-	rows, err := r.DB.Query(ctx, "SELECT id, title, description FROM backend_schema.topics")
+func (r *TopicRepository) GetAll(ctx context.Context, page pagination.Page) ([]topic.Topic, string, error) {
+	var afterTS *time.Time
+	var afterID int
+	if page.After != nil {
+		afterTS, afterID = &page.After.Timestamp, page.After.ID
+	}
+
+	rows, err := r.DB.Query(ctx, `SELECT id, title, description, is_qa, created_at FROM backend_schema.topics
+		WHERE deleted_at IS NULL AND ($1::timestamptz IS NULL OR (created_at, id) > ($1, $2))
+		ORDER BY created_at, id LIMIT $3`,
+		afterTS, afterID, page.Limit+1)
 	if err != nil {
-		r.logger.Fatal("error:", zap.Error(err))
-		return nil, err
+		r.logger.Error("error:", zap.Error(err))
+		return nil, "", err
 	}
 	defer rows.Close()
 
 	var topics []topic.Topic
 	for rows.Next() {
 		var t topic.Topic
-		if err := rows.Scan(&t.ID, &t.Title, &t.Description); err != nil {
-			r.logger.Fatal("error:", zap.Error(err))
-			return nil, err
+		if err := rows.Scan(&t.ID, &t.Title, &t.Description, &t.QA, &t.CreatedAt); err != nil {
+			r.logger.Error("error:", zap.Error(err))
+			return nil, "", err
 		}
 		topics = append(topics, t)
 	}
-	return topics, nil
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	topics, next := pagination.Trim(topics, page.Limit, func(t topic.Topic) pagination.Cursor {
+		return pagination.Cursor{Timestamp: t.CreatedAt, ID: t.ID}
+	})
+	return topics, next, nil
 }
 
-func (r *TopicRepository) Create(ctx context.Context, title, description string) error {
-	_, err := r.DB.Exec(ctx,
-		"INSERT INTO backend_schema.topics (title, description) VALUES ($1, $2)",
-		title, description)
-	return err
+func (r *TopicRepository) GetByID(ctx context.Context, id int) (topic.Topic, error) {
+	var t topic.Topic
+	err := r.DB.QueryRow(ctx, "SELECT id, title, description, is_qa, created_at FROM backend_schema.topics WHERE id = $1 AND deleted_at IS NULL", id).
+		Scan(&t.ID, &t.Title, &t.Description, &t.QA, &t.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return topic.Topic{}, topic.ErrNotFound
+	}
+	return t, err
 }
 
-func (r *TopicRepository) Delete(ctx context.Context, id int64) error {
+func (r *TopicRepository) Create(ctx context.Context, title, description string, qa bool) error {
 	_, err := r.DB.Exec(ctx,
-		"DELETE FROM backend_schema.topics WHERE id = $1", id)
+		"INSERT INTO backend_schema.topics (title, description, is_qa) VALUES ($1, $2, $3)",
+		title, description, qa)
 	return err
 }
+
+// SetQA turns Q&A mode of a topic on or off.
+func (r *TopicRepository) SetQA(ctx context.Context, id int, qa bool) error {
+	tag, err := r.DB.Exec(ctx, "UPDATE backend_schema.topics SET is_qa = $2 WHERE id = $1 AND deleted_at IS NULL", id, qa)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return topic.ErrNotFound
+	}
+	return nil
+}
+
+// Delete moves a topic to the trash along with its posts and their comments.
+func (r *TopicRepository) Delete(ctx context.Context, id int64, deletedBy string) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `UPDATE backend_schema.topics SET deleted_at = now(), deleted_by = $2
+		WHERE id = $1 AND deleted_at IS NULL`, id, deletedBy)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return topic.ErrNotFound
+	}
+	_, err = tx.Exec(ctx, `UPDATE backend_schema.comments SET deleted_at = now(), deleted_by = $2
+		WHERE deleted_at IS NULL AND post_id IN (
+			SELECT id FROM backend_schema.posts WHERE topic_id = $1 AND deleted_at IS NULL)`, id, deletedBy)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `UPDATE backend_schema.posts SET deleted_at = now(), deleted_by = $2
+		WHERE topic_id = $1 AND deleted_at IS NULL`, id, deletedBy)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Restore brings a topic back from the trash together with the posts and
+// comments that were deleted with it.
+func (r *TopicRepository) Restore(ctx context.Context, id int64) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var deletedAt time.Time
+	err = tx.QueryRow(ctx, `SELECT deleted_at FROM backend_schema.topics
+		WHERE id = $1 AND deleted_at IS NOT NULL FOR UPDATE`, id).Scan(&deletedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return trash.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE backend_schema.comments SET deleted_at = NULL, deleted_by = NULL
+		WHERE deleted_at = $2 AND post_id IN (
+			SELECT id FROM backend_schema.posts WHERE topic_id = $1 AND deleted_at = $2)`, id, deletedAt)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `UPDATE backend_schema.posts SET deleted_at = NULL, deleted_by = NULL
+		WHERE topic_id = $1 AND deleted_at = $2`, id, deletedAt)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `UPDATE backend_schema.topics SET deleted_at = NULL, deleted_by = NULL WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
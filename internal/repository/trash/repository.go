@@ -0,0 +1,105 @@
+package trash
+
+import (
+	"context"
+	"time"
+
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/trash"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/pagination"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type Repository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func New(db *pgxpool.Pool, logger *zap.Logger) *Repository {
+	return &Repository{db: db, logger: logger}
+}
+
+// List returns the deleted topics, posts and comments of type typ, or of
+// every type when typ is empty, most recently deleted first. Items deleted
+// together with their topic or post are left out. A topic, a post and a
+// comment may share an id, so pages are keyed on the type as well.
+func (r *Repository) List(ctx context.Context, typ string, page pagination.Page) ([]trash.Item, string, error) {
+	var afterTS *time.Time
+	var afterID int
+	var afterType string
+	if page.After != nil {
+		afterTS, afterID, afterType = &page.After.Timestamp, page.After.ID, page.After.Type
+	}
+
+	rows, err := r.db.Query(ctx, `SELECT type, id, topic_id, post_id, title, excerpt, username, deleted_at, deleted_by FROM (
+			SELECT 'topic' AS type, t.id, NULL::int AS topic_id, NULL::int AS post_id, t.title,
+				t.description AS excerpt, '' AS username, t.deleted_at, COALESCE(t.deleted_by, '') AS deleted_by
+			FROM backend_schema.topics t
+			WHERE t.deleted_at IS NOT NULL
+			UNION ALL
+			SELECT 'post', p.id, p.topic_id, NULL, p.title, p.content, p.username, p.deleted_at, COALESCE(p.deleted_by, '')
+			FROM backend_schema.posts p
+			JOIN backend_schema.topics t ON t.id = p.topic_id
+			WHERE p.deleted_at IS NOT NULL AND t.deleted_at IS DISTINCT FROM p.deleted_at
+			UNION ALL
+			SELECT 'comment', c.id, p.topic_id, c.post_id, p.title, c.content, c.username, c.deleted_at, COALESCE(c.deleted_by, '')
+			FROM backend_schema.comments c
+			JOIN backend_schema.posts p ON p.id = c.post_id
+			WHERE c.deleted_at IS NOT NULL AND p.deleted_at IS DISTINCT FROM c.deleted_at
+		) items
+		WHERE ($1 = '' OR type = $1) AND ($2::timestamptz IS NULL OR (deleted_at, type, id) < ($2, $5, $3))
+		ORDER BY deleted_at DESC, type DESC, id DESC LIMIT $4`,
+		typ, afterTS, afterID, page.Limit+1, afterType)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var items []trash.Item
+	for rows.Next() {
+		var it trash.Item
+		if err := rows.Scan(&it.Type, &it.ID, &it.TopicID, &it.PostID, &it.Title, &it.Excerpt,
+			&it.Username, &it.DeletedAt, &it.DeletedBy); err != nil {
+			return nil, "", err
+		}
+		items = append(items, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	items, next := pagination.Trim(items, page.Limit, func(it trash.Item) pagination.Cursor {
+		return pagination.Cursor{Timestamp: it.DeletedAt, ID: it.ID, Type: it.Type}
+	})
+	return items, next, nil
+}
+
+// Purge removes for good what was deleted before the given time. Comments go
+// first, replies before their parents, so that a purged comment never takes
+// replies that are still visible or not yet due with it.
+func (r *Repository) Purge(ctx context.Context, before time.Time) (trash.Purged, error) {
+	var purged trash.Purged
+	for {
+		tag, err := r.db.Exec(ctx, `DELETE FROM backend_schema.comments c
+			WHERE c.deleted_at < $1 AND NOT EXISTS (SELECT 1 FROM backend_schema.comments r WHERE r.parent_id = c.id)`, before)
+		if err != nil {
+			return purged, err
+		}
+		if tag.RowsAffected() == 0 {
+			break
+		}
+		purged.Comments += tag.RowsAffected()
+	}
+
+	tag, err := r.db.Exec(ctx, `DELETE FROM backend_schema.posts WHERE deleted_at < $1`, before)
+	if err != nil {
+		return purged, err
+	}
+	purged.Posts = tag.RowsAffected()
+
+	tag, err = r.db.Exec(ctx, `DELETE FROM backend_schema.topics WHERE deleted_at < $1`, before)
+	if err != nil {
+		return purged, err
+	}
+	purged.Topics = tag.RowsAffected()
+	return purged, nil
+}
@@ -0,0 +1,68 @@
+package user
+
+import (
+	"context"
+	"errors"
+
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/user"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type Repository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func New(db *pgxpool.Pool, logger *zap.Logger) *Repository {
+	return &Repository{db: db, logger: logger}
+}
+
+// Upsert records the current username and role of a user. A username taken
+// over from a user who has since been renamed is freed first: the stale row
+// gets "#<id>" until its user shows up again with their new name.
+func (r *Repository) Upsert(ctx context.Context, u user.User) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx,
+		`UPDATE backend_schema.users SET username = '#' || id WHERE username = $2 AND id <> $1`,
+		u.ID, u.Username)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx,
+		`INSERT INTO backend_schema.users (id, username, role, last_seen_at) VALUES ($1, $2, $3, now())
+		 ON CONFLICT (id) DO UPDATE SET username = EXCLUDED.username, role = EXCLUDED.role, last_seen_at = now()`,
+		u.ID, u.Username, u.Role)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *Repository) GetByID(ctx context.Context, id int32) (user.User, error) {
+	var u user.User
+	err := r.db.QueryRow(ctx,
+		`SELECT id, username, role, last_seen_at FROM backend_schema.users WHERE id = $1`, id).
+		Scan(&u.ID, &u.Username, &u.Role, &u.LastSeenAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return user.User{}, user.ErrNotFound
+	}
+	return u, err
+}
+
+func (r *Repository) GetByUsername(ctx context.Context, username string) (user.User, error) {
+	var u user.User
+	err := r.db.QueryRow(ctx,
+		`SELECT id, username, role, last_seen_at FROM backend_schema.users WHERE username = $1`, username).
+		Scan(&u.ID, &u.Username, &u.Role, &u.LastSeenAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return user.User{}, user.ErrNotFound
+	}
+	return u, err
+}
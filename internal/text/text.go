@@ -0,0 +1,54 @@
+// Package text holds helpers shared by the post, comment and chat content pipelines.
+package text
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Excerpt cuts s to at most n characters, ending with "…" when shortened.
+func Excerpt(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
+
+// maxMentionLength is the longest username recognised after an "@".
+const maxMentionLength = 64
+
+// Mentions returns the usernames mentioned as "@username" in s, each once, in
+// order of first appearance. An "@" only starts a mention at the beginning of
+// s or after a character that cannot be part of a username, so e-mail
+// addresses are not mistaken for mentions. Trailing dots and dashes are taken
+// as punctuation.
+func Mentions(s string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	prev := ' '
+	for i, r := range s {
+		if r != '@' || isNameRune(prev) {
+			prev = r
+			continue
+		}
+		prev = r
+
+		rest := s[i+1:]
+		end := strings.IndexFunc(rest, func(r rune) bool { return !isNameRune(r) })
+		if end < 0 {
+			end = len(rest)
+		}
+		name := strings.TrimRight(rest[:end], ".-")
+		if name == "" || utf8.RuneCountInString(name) > maxMentionLength || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+func isNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' || r == '-'
+}
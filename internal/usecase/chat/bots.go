@@ -0,0 +1,67 @@
+package chat
+
+import (
+	"context"
+	"time"
+
+	domain "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/chat"
+	"go.uber.org/zap"
+)
+
+const (
+	// botTimeout bounds how long a bot may take to react to one message.
+	botTimeout = 10 * time.Second
+	// maxBotRuns bounds how many bot calls run at once across all messages.
+	maxBotRuns = 32
+)
+
+// Bot is an in-process participant that sees every message stored in any
+// room. Its replies are posted to the same room under the bot's name as
+// KindBot messages, which are not shown to bots again.
+type Bot interface {
+	Name() string
+	OnMessage(ctx context.Context, msg domain.ChatMessage) ([]string, error)
+}
+
+// RegisterBot adds a bot. Bots must be registered before the server starts
+// accepting messages.
+func (u *UseCase) RegisterBot(bot Bot) {
+	u.bots = append(u.bots, bot)
+}
+
+// runBots passes a message to every bot in the background. When maxBotRuns
+// calls are already in flight the message is not passed to the remaining bots.
+func (u *UseCase) runBots(msg domain.ChatMessage) {
+	if msg.Kind == domain.KindBot {
+		return
+	}
+	for _, bot := range u.bots {
+		select {
+		case u.botSlots <- struct{}{}:
+			go u.runBot(bot, msg)
+		default:
+			u.logger.Warn("Chat bots busy, skipping message", zap.String("bot", bot.Name()), zap.Int("messageID", msg.ID))
+		}
+	}
+}
+
+func (u *UseCase) runBot(bot Bot, msg domain.ChatMessage) {
+	defer func() { <-u.botSlots }()
+	defer func() {
+		if r := recover(); r != nil {
+			u.logger.Error("Chat bot panicked", zap.String("bot", bot.Name()), zap.Any("panic", r))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), botTimeout)
+	defer cancel()
+
+	replies, err := bot.OnMessage(ctx, msg)
+	if err != nil {
+		u.logger.Error("Chat bot failed", zap.String("bot", bot.Name()), zap.Int("messageID", msg.ID), zap.Error(err))
+		return
+	}
+	for _, reply := range replies {
+		u.post(ctx, domain.ChatMessage{RoomID: msg.RoomID, Username: bot.Name(), Kind: domain.KindBot, Content: reply})
+	}
+}
@@ -0,0 +1,202 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	domain "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/chat"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/post"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/topic"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/text"
+	"go.uber.org/zap"
+)
+
+// Command is a chat message starting with "/", e.g. "/topic 12" has Name
+// "topic" and Args "12".
+type Command struct {
+	Name     string
+	Args     string
+	RoomID   int
+	UserID   int32
+	Username string
+	Role     string
+}
+
+// CommandResult is what a command produced. Post, when set, is stored and
+// broadcast to the room as the issuer's message; RoomID and Username are
+// filled in by the usecase. Notice is feedback shown only to the issuer.
+type CommandResult struct {
+	Post   *domain.ChatMessage
+	Notice string
+}
+
+// CommandHandler executes one slash command.
+type CommandHandler interface {
+	Handle(ctx context.Context, cmd Command) (CommandResult, error)
+}
+
+// CommandFunc adapts a function to CommandHandler.
+type CommandFunc func(ctx context.Context, cmd Command) (CommandResult, error)
+
+func (f CommandFunc) Handle(ctx context.Context, cmd Command) (CommandResult, error) {
+	return f(ctx, cmd)
+}
+
+// RegisterCommand makes "/name" run handler, replacing any earlier handler.
+// Commands must be registered before the server starts accepting messages.
+func (u *UseCase) RegisterCommand(name string, handler CommandHandler) {
+	u.commands[strings.ToLower(name)] = handler
+}
+
+// Submit posts what a user typed into a room. Text starting with "/" runs a
+// command instead of being stored; "//" escapes a leading slash. The result's
+// Post is the stored message, if any.
+func (u *UseCase) Submit(ctx context.Context, cmd Command, content string) (CommandResult, error) {
+	if !strings.HasPrefix(content, "/") || strings.HasPrefix(content, "//") {
+		msg, err := u.SendMessage(ctx, cmd.RoomID, cmd.Username, strings.TrimPrefix(content, "/"))
+		if msg.ID == 0 {
+			return CommandResult{}, err
+		}
+		return CommandResult{Post: &msg}, err
+	}
+
+	name, args, _ := strings.Cut(strings.TrimPrefix(content, "/"), " ")
+	cmd.Name, cmd.Args = strings.ToLower(name), strings.TrimSpace(args)
+	handler, ok := u.commands[cmd.Name]
+	if !ok {
+		return CommandResult{}, fmt.Errorf("%w /%s", domain.ErrUnknownCommand, cmd.Name)
+	}
+
+	res, err := handler.Handle(ctx, cmd)
+	if err != nil {
+		if !errors.Is(err, domain.ErrCommandUsage) && !errors.Is(err, domain.ErrForbidden) {
+			u.logger.Error("Chat command failed", zap.String("command", cmd.Name), zap.String("username", cmd.Username), zap.Error(err))
+		}
+		return CommandResult{}, err
+	}
+	if res.Post == nil {
+		return res, nil
+	}
+
+	res.Post.RoomID, res.Post.Username = cmd.RoomID, cmd.Username
+	msg, err := u.post(ctx, *res.Post)
+	if msg.ID == 0 {
+		return CommandResult{}, err
+	}
+	res.Post = &msg
+	return res, err
+}
+
+func usage(text string) error {
+	return fmt.Errorf("%w: %s", domain.ErrCommandUsage, text)
+}
+
+// meCommand posts an action, "/me waves" is shown as "* user waves".
+func meCommand(ctx context.Context, cmd Command) (CommandResult, error) {
+	if cmd.Args == "" {
+		return CommandResult{}, usage("/me <action>")
+	}
+	return CommandResult{Post: &domain.ChatMessage{Kind: domain.KindAction, Content: cmd.Args}}, nil
+}
+
+// muteCommand lets ADMINs mute a user in the current room: "/mute <username> <duration> [reason]".
+func (u *UseCase) muteCommand(ctx context.Context, cmd Command) (CommandResult, error) {
+	if cmd.Role != "ADMIN" {
+		return CommandResult{}, domain.ErrForbidden
+	}
+	fields := strings.SplitN(cmd.Args, " ", 3)
+	if len(fields) < 2 {
+		return CommandResult{}, usage("/mute <username> <duration> [reason]")
+	}
+	d, err := time.ParseDuration(fields[1])
+	if err != nil || d <= 0 {
+		return CommandResult{}, usage("/mute <username> <duration> [reason], e.g. /mute bob 10m spam")
+	}
+	var reason string
+	if len(fields) == 3 {
+		reason = fields[2]
+	}
+
+	m, err := u.Mute(ctx, &cmd.RoomID, fields[0], d, reason, cmd.Username)
+	if m.ID == 0 {
+		return CommandResult{}, err
+	}
+	return CommandResult{Notice: fmt.Sprintf("%s is muted until %s", m.Username, m.Until.UTC().Format(time.RFC3339))}, nil
+}
+
+// TopicLookup finds forum topics for the /topic command.
+type TopicLookup interface {
+	GetByID(ctx context.Context, id int) (topic.Topic, error)
+}
+
+// TopicCommand links a forum topic: "/topic <id>".
+func TopicCommand(topics TopicLookup) CommandHandler {
+	return CommandFunc(func(ctx context.Context, cmd Command) (CommandResult, error) {
+		id, err := strconv.Atoi(cmd.Args)
+		if err != nil {
+			return CommandResult{}, usage("/topic <id>")
+		}
+		t, err := topics.GetByID(ctx, id)
+		if errors.Is(err, topic.ErrNotFound) {
+			return CommandResult{Notice: fmt.Sprintf("topic %d not found", id)}, nil
+		}
+		if err != nil {
+			return CommandResult{}, err
+		}
+		title := text.Excerpt(t.Title, excerptLength)
+		return attachmentPost(domain.KindTopic, title, domain.TopicPreview{
+			ID:          t.ID,
+			Title:       title,
+			Description: text.Excerpt(t.Description, excerptLength),
+		})
+	})
+}
+
+// PostLookup finds forum posts for the /post command.
+type PostLookup interface {
+	GetByID(ctx context.Context, postID int) (post.Post, error)
+}
+
+// excerptLength is the number of characters of a title, a topic description
+// or a post shown in a preview. It keeps unfurled messages within the NOTIFY
+// payload limit, as maxContentLength does for typed ones.
+const excerptLength = 280
+
+// PostCommand unfurls a preview of a forum post: "/post <id>".
+func PostCommand(posts PostLookup) CommandHandler {
+	return CommandFunc(func(ctx context.Context, cmd Command) (CommandResult, error) {
+		id, err := strconv.Atoi(cmd.Args)
+		if err != nil {
+			return CommandResult{}, usage("/post <id>")
+		}
+		p, err := posts.GetByID(ctx, id)
+		if errors.Is(err, post.ErrNotFound) {
+			return CommandResult{Notice: fmt.Sprintf("post %d not found", id)}, nil
+		}
+		if err != nil {
+			return CommandResult{}, err
+		}
+		title := text.Excerpt(p.Title, excerptLength)
+		return attachmentPost(domain.KindPost, title, domain.PostPreview{
+			ID:        p.ID,
+			TopicID:   p.TopicID,
+			Title:     title,
+			Excerpt:   text.Excerpt(p.Content, excerptLength),
+			Username:  p.Username,
+			Timestamp: p.Timestamp,
+		})
+	})
+}
+
+func attachmentPost(kind, content string, attachment any) (CommandResult, error) {
+	data, err := json.Marshal(attachment)
+	if err != nil {
+		return CommandResult{}, err
+	}
+	return CommandResult{Post: &domain.ChatMessage{Kind: kind, Content: content, Attachment: data}}, nil
+}
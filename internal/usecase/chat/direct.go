@@ -0,0 +1,100 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"unicode/utf8"
+
+	domain "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/chat"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/event"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/user"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/pagination"
+	"go.uber.org/zap"
+)
+
+// ResolvePeer finds the other participant of a direct conversation by username.
+// Users can only be reached once they have signed in to the forum at least once.
+func (u *UseCase) ResolvePeer(ctx context.Context, userID int32, username string) (user.User, error) {
+	peer, err := u.users.GetByUsername(ctx, username)
+	if errors.Is(err, user.ErrNotFound) {
+		return user.User{}, domain.ErrInvalidPeer
+	}
+	if err != nil {
+		u.logger.Error("Failed to resolve direct message peer", zap.String("peer", username), zap.Error(err))
+		return user.User{}, err
+	}
+	if peer.ID == userID {
+		return user.User{}, domain.ErrInvalidPeer
+	}
+	return peer, nil
+}
+
+// SendDirectMessage stores a private message and delivers it to both participants.
+func (u *UseCase) SendDirectMessage(ctx context.Context, senderID int32, recipient, content string) (domain.DirectMessage, error) {
+	if utf8.RuneCountInString(content) > maxContentLength {
+		return domain.DirectMessage{}, domain.ErrMessageTooLong
+	}
+	peer, err := u.ResolvePeer(ctx, senderID, recipient)
+	if err != nil {
+		return domain.DirectMessage{}, err
+	}
+
+	msg, err := u.repo.SaveDirectMessage(ctx, senderID, peer.ID, content)
+	if err != nil {
+		u.logger.Error("Failed to save direct message", zap.Int32("senderID", senderID), zap.Int32("recipientID", peer.ID), zap.Error(err))
+		return domain.DirectMessage{}, err
+	}
+	u.logger.Info("Direct message saved", zap.Int32("senderID", senderID), zap.Int32("recipientID", peer.ID))
+
+	e := event.Event{Type: event.DirectMessage, ID: msg.ID, UserIDs: []int32{senderID, peer.ID}}
+	return msg, u.publish(ctx, e, msg)
+}
+
+// GetDirectMessages returns the conversation between userID and the named peer.
+func (u *UseCase) GetDirectMessages(ctx context.Context, userID int32, peerName string, q domain.HistoryQuery) ([]domain.DirectMessage, error) {
+	peer, err := u.ResolvePeer(ctx, userID, peerName)
+	if err != nil {
+		return nil, err
+	}
+
+	q.Limit = u.limits.Apply(pagination.Page{Limit: q.Limit}).Limit
+	msgs, err := u.repo.GetDirectMessages(ctx, userID, peer.ID, q)
+	if err != nil {
+		u.logger.Error("Failed to get direct messages", zap.Int32("userID", userID), zap.Int32("peerID", peer.ID), zap.Error(err))
+		return nil, err
+	}
+	return msgs, nil
+}
+
+func (u *UseCase) GetInbox(ctx context.Context, userID int32) ([]domain.Conversation, error) {
+	inbox, err := u.repo.GetInbox(ctx, userID)
+	if err != nil {
+		u.logger.Error("Failed to get direct message inbox", zap.Int32("userID", userID), zap.Error(err))
+		return nil, err
+	}
+	return inbox, nil
+}
+
+// MarkDirectRead marks the peer's messages to userID as read and returns how many changed.
+func (u *UseCase) MarkDirectRead(ctx context.Context, userID int32, peerName string) (int64, error) {
+	peer, err := u.ResolvePeer(ctx, userID, peerName)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := u.repo.MarkDirectRead(ctx, userID, peer.ID)
+	if err != nil {
+		u.logger.Error("Failed to mark direct messages read", zap.Int32("userID", userID), zap.Int32("peerID", peer.ID), zap.Error(err))
+		return 0, err
+	}
+	return n, nil
+}
+
+func (u *UseCase) CountUnreadDirect(ctx context.Context, userID int32) (int, error) {
+	n, err := u.repo.CountUnreadDirect(ctx, userID)
+	if err != nil {
+		u.logger.Error("Failed to count unread direct messages", zap.Int32("userID", userID), zap.Error(err))
+		return 0, err
+	}
+	return n, nil
+}
@@ -0,0 +1,141 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	domain "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/chat"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/event"
+	"go.uber.org/zap"
+)
+
+// CheckSend returns ErrBanned, ErrMuted or ErrSlowMode when the user may not
+// post in the room right now, and ErrRoomNotFound once the room's topic is in
+// the trash. Mute and slow mode errors say how long to wait.
+func (u *UseCase) CheckSend(ctx context.Context, roomID int, userID int32, username string) error {
+	s, err := u.repo.GetSendRestrictions(ctx, roomID, userID, username)
+	if errors.Is(err, domain.ErrRoomNotFound) {
+		return err
+	}
+	if err != nil {
+		u.logger.Error("Failed to check chat restrictions", zap.Int("roomID", roomID), zap.String("username", username), zap.Error(err))
+		return err
+	}
+
+	switch {
+	case s.Banned:
+		return domain.ErrBanned
+	case s.MutedUntil != nil:
+		return fmt.Errorf("%w until %s", domain.ErrMuted, s.MutedUntil.UTC().Format(time.RFC3339))
+	case s.SlowMode > 0 && s.LastMessageAt != nil:
+		wait := time.Until(s.LastMessageAt.Add(time.Duration(s.SlowMode) * time.Second))
+		if wait > 0 {
+			return fmt.Errorf("%w, wait %ds", domain.ErrSlowMode, int(wait.Seconds())+1)
+		}
+	}
+	return nil
+}
+
+func (u *UseCase) IsBanned(ctx context.Context, roomID int, userID int32) (bool, error) {
+	banned, err := u.repo.IsBanned(ctx, roomID, userID)
+	if err != nil {
+		u.logger.Error("Failed to check chat ban", zap.Int("roomID", roomID), zap.Int32("userID", userID), zap.Error(err))
+	}
+	return banned, err
+}
+
+// Mute stops a user from posting for d, in one room or, with a nil roomID, everywhere.
+func (u *UseCase) Mute(ctx context.Context, roomID *int, username string, d time.Duration, reason, mutedBy string) (domain.Mute, error) {
+	target, err := u.users.GetByUsername(ctx, username)
+	if err != nil {
+		return domain.Mute{}, err
+	}
+
+	m, err := u.repo.AddMute(ctx, domain.Mute{
+		RoomID:    roomID,
+		UserID:    target.ID,
+		Username:  target.Username,
+		Until:     time.Now().Add(d),
+		Reason:    reason,
+		CreatedBy: mutedBy,
+	})
+	if err != nil {
+		u.logger.Error("Failed to mute chat user", zap.String("username", username), zap.Error(err))
+		return domain.Mute{}, err
+	}
+	u.logger.Info("Chat user muted", zap.String("username", username), zap.Duration("duration", d), zap.String("by", mutedBy))
+
+	return m, u.publish(ctx, event.Event{Type: event.ChatUserMuted, ID: m.ID, UserIDs: []int32{target.ID}}, m)
+}
+
+// Unmute lifts a user's mutes in a room, or the global ones when roomID is nil.
+func (u *UseCase) Unmute(ctx context.Context, roomID *int, username string) (int64, error) {
+	target, err := u.users.GetByUsername(ctx, username)
+	if err != nil {
+		return 0, err
+	}
+	n, err := u.repo.RemoveMutes(ctx, roomID, target.ID)
+	if err != nil {
+		u.logger.Error("Failed to unmute chat user", zap.String("username", username), zap.Error(err))
+	}
+	return n, err
+}
+
+// Ban keeps a user out of a room and disconnects their open sockets in it.
+func (u *UseCase) Ban(ctx context.Context, roomID int, username, reason, bannedBy string) (domain.Ban, error) {
+	target, err := u.users.GetByUsername(ctx, username)
+	if err != nil {
+		return domain.Ban{}, err
+	}
+
+	b, err := u.repo.AddBan(ctx, domain.Ban{
+		RoomID:    roomID,
+		UserID:    target.ID,
+		Username:  target.Username,
+		Reason:    reason,
+		CreatedBy: bannedBy,
+	})
+	if err != nil {
+		u.logger.Error("Failed to ban chat user", zap.String("username", username), zap.Int("roomID", roomID), zap.Error(err))
+		return domain.Ban{}, err
+	}
+	u.logger.Info("Chat user banned", zap.String("username", username), zap.Int("roomID", roomID), zap.String("by", bannedBy))
+
+	return b, u.publish(ctx, event.Event{Type: event.ChatUserBanned, RoomID: roomID, UserIDs: []int32{target.ID}}, b)
+}
+
+func (u *UseCase) Unban(ctx context.Context, roomID int, username string) (int64, error) {
+	target, err := u.users.GetByUsername(ctx, username)
+	if err != nil {
+		return 0, err
+	}
+	n, err := u.repo.RemoveBan(ctx, roomID, target.ID)
+	if err != nil {
+		u.logger.Error("Failed to unban chat user", zap.String("username", username), zap.Int("roomID", roomID), zap.Error(err))
+	}
+	return n, err
+}
+
+// SetSlowMode sets the minimum number of seconds between a user's messages
+// in a room; 0 turns slow mode off.
+func (u *UseCase) SetSlowMode(ctx context.Context, roomID, seconds int, setBy string) (domain.Room, error) {
+	room, err := u.repo.SetSlowMode(ctx, roomID, seconds)
+	if err != nil {
+		u.logger.Error("Failed to set chat slow mode", zap.Int("roomID", roomID), zap.Error(err))
+		return domain.Room{}, err
+	}
+	u.logger.Info("Chat slow mode set", zap.Int("roomID", roomID), zap.Int("seconds", seconds), zap.String("by", setBy))
+
+	return room, u.publish(ctx, event.Event{Type: event.ChatRoomUpdated, ID: room.ID, RoomID: room.ID}, room)
+}
+
+// GetModeration lists active mutes and bans, for one room when roomID is set.
+func (u *UseCase) GetModeration(ctx context.Context, roomID *int) (domain.Moderation, error) {
+	mod, err := u.repo.GetModeration(ctx, roomID)
+	if err != nil {
+		u.logger.Error("Failed to list chat moderation", zap.Error(err))
+	}
+	return mod, err
+}
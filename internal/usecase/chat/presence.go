@@ -0,0 +1,109 @@
+package chat
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"time"
+
+	domain "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/chat"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/event"
+	"go.uber.org/zap"
+)
+
+// presenceMissedBeats is how many refresh intervals an instance may miss
+// before its presence rows are considered stale.
+const presenceMissedBeats = 3
+
+func newInstanceID() string {
+	host, _ := os.Hostname()
+	b := make([]byte, 4)
+	rand.Read(b)
+	return host + "-" + hex.EncodeToString(b)
+}
+
+// Connect records a new connection of a user to a room. The user's first
+// connection, on any instance, announces them to the room.
+func (u *UseCase) Connect(ctx context.Context, p domain.Presence) error {
+	total, err := u.repo.AddPresence(ctx, u.instanceID, p)
+	if err != nil {
+		u.logger.Error("Failed to record chat presence", zap.Int("roomID", p.RoomID), zap.String("username", p.Username), zap.Error(err))
+		return err
+	}
+	if total > 1 {
+		return nil
+	}
+	return u.publish(ctx, event.Event{Type: event.ChatPresenceJoin, RoomID: p.RoomID}, p)
+}
+
+// Disconnect removes a connection recorded by Connect. The user's last
+// connection announces that they left the room.
+func (u *UseCase) Disconnect(ctx context.Context, p domain.Presence) error {
+	total, err := u.repo.RemovePresence(ctx, u.instanceID, p)
+	if err != nil {
+		u.logger.Error("Failed to remove chat presence", zap.Int("roomID", p.RoomID), zap.String("username", p.Username), zap.Error(err))
+		return err
+	}
+	if total > 0 {
+		return nil
+	}
+	return u.publish(ctx, event.Event{Type: event.ChatPresenceLeave, RoomID: p.RoomID}, p)
+}
+
+// Online returns the users connected to a room on any instance.
+func (u *UseCase) Online(ctx context.Context, roomID int) ([]domain.Presence, error) {
+	online, err := u.repo.GetPresence(ctx, roomID)
+	if err != nil {
+		u.logger.Error("Failed to get chat presence", zap.Int("roomID", roomID), zap.Error(err))
+		return nil, err
+	}
+	return online, nil
+}
+
+// SetTyping tells the room that a user started or stopped typing.
+func (u *UseCase) SetTyping(ctx context.Context, roomID int, username string, typing bool) error {
+	t := event.ChatTypingStop
+	if typing {
+		t = event.ChatTypingStart
+	}
+	return u.publish(ctx, event.Event{Type: t, RoomID: roomID}, domain.Typing{RoomID: roomID, Username: username})
+}
+
+// KeepPresence refreshes this instance's presence rows every interval and
+// purges rows other instances stopped refreshing. When ctx is cancelled it
+// removes this instance's rows so its users do not linger as online.
+func (u *UseCase) KeepPresence(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := u.repo.TouchPresence(ctx, u.instanceID); err != nil {
+				u.logger.Error("Failed to refresh chat presence", zap.Error(err))
+			}
+			gone, err := u.repo.PurgePresence(ctx, presenceMissedBeats*interval)
+			if err != nil {
+				u.logger.Error("Failed to purge stale chat presence", zap.Error(err))
+			}
+			u.announceLeft(ctx, gone)
+
+		case <-ctx.Done():
+			cleanupCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			gone, err := u.repo.ClearPresence(cleanupCtx, u.instanceID)
+			if err != nil {
+				u.logger.Error("Failed to clear chat presence", zap.Error(err))
+			}
+			u.announceLeft(cleanupCtx, gone)
+			return
+		}
+	}
+}
+
+func (u *UseCase) announceLeft(ctx context.Context, gone []domain.Presence) {
+	for _, p := range gone {
+		u.publish(ctx, event.Event{Type: event.ChatPresenceLeave, RoomID: p.RoomID}, p)
+	}
+}
@@ -2,39 +2,220 @@ package chat
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	domain "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/chat"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/event"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/user"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/pagination"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/text"
 	"go.uber.org/zap"
 )
 
 type Repository interface {
-	SaveMessage(ctx context.Context, username, content string) error
-	GetRecentMessages(ctx context.Context) ([]domain.ChatMessage, error)
-	DeleteOldMessages(ctx context.Context, olderThan time.Duration) error
+	SaveMessage(ctx context.Context, msg domain.ChatMessage) (domain.ChatMessage, error)
+	GetRecentMessages(ctx context.Context, roomID int, q domain.HistoryQuery) ([]domain.ChatMessage, error)
+	GetMessage(ctx context.Context, messageID int) (domain.ChatMessage, error)
+	LatestMessageID(ctx context.Context, roomID int) (int, error)
+	EditMessage(ctx context.Context, messageID int, content string) (domain.ChatMessage, error)
+	DeleteMessage(ctx context.Context, messageID int, deletedBy string) error
+
+	GetRoomByName(ctx context.Context, name string) (domain.Room, error)
+	EnsureTopicRoom(ctx context.Context, topicID int) (domain.Room, error)
+	CreateRoom(ctx context.Context, name, title, createdBy string) (domain.Room, error)
+	ListRooms(ctx context.Context) ([]domain.Room, error)
+	JoinRoom(ctx context.Context, roomID int, userID int32, username string) error
+	GetMembers(ctx context.Context, roomID int) ([]domain.Member, error)
+	SetSlowMode(ctx context.Context, roomID, seconds int) (domain.Room, error)
+	SetRetention(ctx context.Context, roomID int, r domain.Retention) (domain.Room, error)
+
+	GetSendRestrictions(ctx context.Context, roomID int, userID int32, username string) (domain.SendRestrictions, error)
+	IsBanned(ctx context.Context, roomID int, userID int32) (bool, error)
+	AddMute(ctx context.Context, m domain.Mute) (domain.Mute, error)
+	RemoveMutes(ctx context.Context, roomID *int, userID int32) (int64, error)
+	AddBan(ctx context.Context, b domain.Ban) (domain.Ban, error)
+	RemoveBan(ctx context.Context, roomID int, userID int32) (int64, error)
+	GetModeration(ctx context.Context, roomID *int) (domain.Moderation, error)
+
+	SaveDirectMessage(ctx context.Context, senderID, recipientID int32, content string) (domain.DirectMessage, error)
+	GetDirectMessages(ctx context.Context, userID, peerID int32, q domain.HistoryQuery) ([]domain.DirectMessage, error)
+	GetInbox(ctx context.Context, userID int32) ([]domain.Conversation, error)
+	MarkDirectRead(ctx context.Context, userID, peerID int32) (int64, error)
+	CountUnreadDirect(ctx context.Context, userID int32) (int, error)
+
+	AddPresence(ctx context.Context, instanceID string, p domain.Presence) (int, error)
+	RemovePresence(ctx context.Context, instanceID string, p domain.Presence) (int, error)
+	GetPresence(ctx context.Context, roomID int) ([]domain.Presence, error)
+	TouchPresence(ctx context.Context, instanceID string) error
+	PurgePresence(ctx context.Context, staleAfter time.Duration) ([]domain.Presence, error)
+	ClearPresence(ctx context.Context, instanceID string) ([]domain.Presence, error)
+}
+
+// UserDirectory resolves usernames to the ids issued by the auth service.
+type UserDirectory interface {
+	GetByUsername(ctx context.Context, username string) (user.User, error)
 }
 
+// Notifier tells users that a chat message mentioned them as @username.
+type Notifier interface {
+	ChatMentioned(ctx context.Context, msg domain.ChatMessage, mentions []string)
+}
+
+// maxContentLength keeps a message, once JSON-encoded, within the NOTIFY payload limit.
+const maxContentLength = 1000
+
+var roomNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{1,63}$`)
+
 type UseCase struct {
-	repo   Repository
-	logger *zap.Logger
+	// instanceID tells this server's presence rows apart from other replicas'.
+	instanceID string
+	repo       Repository
+	users      UserDirectory
+	publisher  event.Publisher
+	notifier   Notifier
+	limits     pagination.Limits
+	editWindow time.Duration
+	logger     *zap.Logger
+
+	commands map[string]CommandHandler
+	bots     []Bot
+	botSlots chan struct{}
 }
 
-func New(repo Repository, logger *zap.Logger) *UseCase {
-	return &UseCase{repo: repo, logger: logger}
+// New creates the chat usecase. Authors may edit or delete their messages for
+// editWindow after sending them.
+func New(repo Repository, users UserDirectory, publisher event.Publisher, notifier Notifier, limits pagination.Limits, editWindow time.Duration, logger *zap.Logger) *UseCase {
+	u := &UseCase{
+		instanceID: newInstanceID(),
+		repo:       repo,
+		users:      users,
+		publisher:  publisher,
+		notifier:   notifier,
+		limits:     limits,
+		editWindow: editWindow,
+		logger:     logger,
+		commands:   make(map[string]CommandHandler),
+		botSlots:   make(chan struct{}, maxBotRuns),
+	}
+	u.RegisterCommand("me", CommandFunc(meCommand))
+	u.RegisterCommand("mute", CommandFunc(u.muteCommand))
+	return u
+}
+
+// SendMessage stores a text message and publishes it to the room on all instances.
+func (u *UseCase) SendMessage(ctx context.Context, roomID int, username, content string) (domain.ChatMessage, error) {
+	return u.post(ctx, domain.ChatMessage{RoomID: roomID, Username: username, Kind: domain.KindText, Content: content})
+}
+
+// post stores a message of any kind, publishes it, notifies the users that a
+// text or action message mentions and passes it to the bots.
+func (u *UseCase) post(ctx context.Context, msg domain.ChatMessage) (domain.ChatMessage, error) {
+	if utf8.RuneCountInString(msg.Content) > maxContentLength {
+		return domain.ChatMessage{}, domain.ErrMessageTooLong
+	}
+
+	msg, err := u.repo.SaveMessage(ctx, msg)
+	if err != nil {
+		u.logger.Error("Failed to save chat message", zap.String("username", msg.Username), zap.Error(err))
+		return domain.ChatMessage{}, err
+	}
+	u.logger.Info("Chat message saved", zap.String("username", msg.Username), zap.Int("roomID", msg.RoomID), zap.String("kind", msg.Kind))
+
+	err = u.publish(ctx, event.Event{Type: event.ChatMessage, ID: msg.ID, RoomID: msg.RoomID}, msg)
+	if msg.Kind == domain.KindText || msg.Kind == domain.KindAction || msg.Kind == domain.KindBot {
+		if mentions := text.Mentions(msg.Content); len(mentions) > 0 {
+			u.notifier.ChatMentioned(ctx, msg, mentions)
+		}
+	}
+	u.runBots(msg)
+	return msg, err
+}
+
+// EditMessage replaces the content of a text message. Only the author can edit
+// a message, only within the edit window and not while muted or banned in its
+// room. Slow mode does not apply to edits.
+func (u *UseCase) EditMessage(ctx context.Context, messageID int, userID int32, username, content string) (domain.ChatMessage, error) {
+	if utf8.RuneCountInString(content) > maxContentLength {
+		return domain.ChatMessage{}, domain.ErrMessageTooLong
+	}
+	msg, err := u.repo.GetMessage(ctx, messageID)
+	if err != nil {
+		return domain.ChatMessage{}, err
+	}
+	if msg.Username != username {
+		return domain.ChatMessage{}, domain.ErrForbidden
+	}
+	if msg.Kind != domain.KindText {
+		return domain.ChatMessage{}, domain.ErrNotEditable
+	}
+	if time.Since(msg.Timestamp) > u.editWindow {
+		return domain.ChatMessage{}, domain.ErrEditWindow
+	}
+	if err := u.CheckSend(ctx, msg.RoomID, userID, username); err != nil && !errors.Is(err, domain.ErrSlowMode) {
+		return domain.ChatMessage{}, err
+	}
+
+	msg, err = u.repo.EditMessage(ctx, messageID, content)
+	if err != nil {
+		u.logger.Error("Failed to edit chat message", zap.Int("messageID", messageID), zap.Error(err))
+		return domain.ChatMessage{}, err
+	}
+	u.logger.Info("Chat message edited", zap.Int("messageID", messageID), zap.String("username", username))
+
+	return msg, u.publish(ctx, event.Event{Type: event.ChatMessageEdited, ID: msg.ID, RoomID: msg.RoomID}, msg)
+}
+
+// DeleteMessage removes a message from its room. Authors can delete their own
+// messages within the edit window; ADMINs can delete any message at any time.
+func (u *UseCase) DeleteMessage(ctx context.Context, messageID int, username, role string) (domain.MessageDeletion, error) {
+	msg, err := u.repo.GetMessage(ctx, messageID)
+	if err != nil {
+		return domain.MessageDeletion{}, err
+	}
+	if role != "ADMIN" {
+		if msg.Username != username {
+			return domain.MessageDeletion{}, domain.ErrForbidden
+		}
+		if time.Since(msg.Timestamp) > u.editWindow {
+			return domain.MessageDeletion{}, domain.ErrEditWindow
+		}
+	}
+
+	if err := u.repo.DeleteMessage(ctx, messageID, username); err != nil {
+		if !errors.Is(err, domain.ErrMessageNotFound) {
+			u.logger.Error("Failed to delete chat message", zap.Int("messageID", messageID), zap.Error(err))
+		}
+		return domain.MessageDeletion{}, err
+	}
+	u.logger.Info("Chat message deleted", zap.Int("messageID", messageID), zap.String("username", username))
+
+	deletion := domain.MessageDeletion{ID: msg.ID, RoomID: msg.RoomID, DeletedBy: username}
+	return deletion, u.publish(ctx, event.Event{Type: event.ChatMessageDeleted, ID: msg.ID, RoomID: msg.RoomID}, deletion)
 }
 
-func (u *UseCase) SendMessage(ctx context.Context, username, content string) error {
-	err := u.repo.SaveMessage(ctx, username, content)
+// publish fills in the event payload and sends it to all instances.
+func (u *UseCase) publish(ctx context.Context, e event.Event, payload any) error {
+	data, err := json.Marshal(payload)
 	if err != nil {
-		u.logger.Error("Failed to save chat message", zap.String("username", username), zap.Error(err))
 		return err
 	}
-	u.logger.Info("Chat message saved", zap.String("username", username))
-	return nil
+	e.Payload = data
+	err = u.publisher.Publish(ctx, e)
+	if err != nil {
+		u.logger.Error("Failed to publish chat event", zap.String("type", e.Type), zap.Int("roomID", e.RoomID), zap.Error(err))
+	}
+	return err
 }
 
-func (u *UseCase) GetMessages(ctx context.Context) ([]domain.ChatMessage, error) {
-	msgs, err := u.repo.GetRecentMessages(ctx)
+func (u *UseCase) GetMessages(ctx context.Context, roomID int, q domain.HistoryQuery) ([]domain.ChatMessage, error) {
+	q.Limit = u.limits.Apply(pagination.Page{Limit: q.Limit}).Limit
+	msgs, err := u.repo.GetRecentMessages(ctx, roomID, q)
 	if err != nil {
 		u.logger.Error("Failed to get chat messages", zap.Error(err))
 		return nil, err
@@ -42,3 +223,105 @@ func (u *UseCase) GetMessages(ctx context.Context) ([]domain.ChatMessage, error)
 	u.logger.Info("Fetched recent chat messages", zap.Int("count", len(msgs)))
 	return msgs, nil
 }
+
+// MessagesSince returns the messages of a room after lastSeenID, oldest
+// first, to replay what a reconnecting client missed. Replays are capped at
+// one maximum-size page; clients further behind get event.ErrTooFarBehind.
+func (u *UseCase) MessagesSince(ctx context.Context, roomID, lastSeenID int) ([]domain.ChatMessage, error) {
+	limit := u.limits.Apply(pagination.Page{Limit: u.limits.Max}).Limit
+	missed, err := u.repo.GetRecentMessages(ctx, roomID, domain.HistoryQuery{SinceID: lastSeenID, Limit: limit + 1})
+	if err != nil {
+		u.logger.Error("Failed to get missed chat messages", zap.Int("roomID", roomID), zap.Int("lastSeenID", lastSeenID), zap.Error(err))
+		return nil, err
+	}
+	if len(missed) > limit {
+		return nil, event.ErrTooFarBehind
+	}
+	return missed, nil
+}
+
+func (u *UseCase) LatestMessageID(ctx context.Context, roomID int) (int, error) {
+	id, err := u.repo.LatestMessageID(ctx, roomID)
+	if err != nil {
+		u.logger.Error("Failed to get latest chat message id", zap.Int("roomID", roomID), zap.Error(err))
+	}
+	return id, err
+}
+
+// ResolveRoom finds a room by name. An empty name means the default room, and
+// "topic-<id>" rooms are created on first use for existing topics.
+func (u *UseCase) ResolveRoom(ctx context.Context, name string) (domain.Room, error) {
+	if name == "" {
+		name = domain.DefaultRoom
+	}
+
+	room, err := u.repo.GetRoomByName(ctx, name)
+	if errors.Is(err, domain.ErrRoomNotFound) {
+		if id, ok := strings.CutPrefix(name, "topic-"); ok {
+			topicID, convErr := strconv.Atoi(id)
+			if convErr != nil {
+				return domain.Room{}, domain.ErrRoomNotFound
+			}
+			room, err = u.repo.EnsureTopicRoom(ctx, topicID)
+		}
+	}
+	if err != nil && !errors.Is(err, domain.ErrRoomNotFound) {
+		u.logger.Error("Failed to resolve chat room", zap.String("room", name), zap.Error(err))
+	}
+	return room, err
+}
+
+// CreateRoom creates an ad-hoc room. Names starting with "topic-" are reserved for topic rooms.
+func (u *UseCase) CreateRoom(ctx context.Context, name, title, username string) (domain.Room, error) {
+	if !roomNamePattern.MatchString(name) || strings.HasPrefix(name, "topic-") {
+		return domain.Room{}, domain.ErrInvalidRoomName
+	}
+	if title == "" {
+		title = name
+	}
+
+	room, err := u.repo.CreateRoom(ctx, name, title, username)
+	if err != nil {
+		u.logger.Error("Failed to create chat room", zap.String("room", name), zap.Error(err))
+		return domain.Room{}, err
+	}
+	u.logger.Info("Chat room created", zap.String("room", name), zap.String("username", username))
+	return room, nil
+}
+
+func (u *UseCase) ListRooms(ctx context.Context) ([]domain.Room, error) {
+	rooms, err := u.repo.ListRooms(ctx)
+	if err != nil {
+		u.logger.Error("Failed to list chat rooms", zap.Error(err))
+		return nil, err
+	}
+	return rooms, nil
+}
+
+// SetRetention replaces a room's overrides of the global retention policy.
+func (u *UseCase) SetRetention(ctx context.Context, roomID int, r domain.Retention, setBy string) (domain.Room, error) {
+	room, err := u.repo.SetRetention(ctx, roomID, r)
+	if err != nil {
+		u.logger.Error("Failed to set chat retention", zap.Int("roomID", roomID), zap.Error(err))
+		return domain.Room{}, err
+	}
+	u.logger.Info("Chat retention set", zap.Int("roomID", roomID), zap.String("by", setBy))
+	return room, nil
+}
+
+func (u *UseCase) JoinRoom(ctx context.Context, roomID int, userID int32, username string) error {
+	err := u.repo.JoinRoom(ctx, roomID, userID, username)
+	if err != nil {
+		u.logger.Error("Failed to record chat room membership", zap.Int("roomID", roomID), zap.String("username", username), zap.Error(err))
+	}
+	return err
+}
+
+func (u *UseCase) GetMembers(ctx context.Context, roomID int) ([]domain.Member, error) {
+	members, err := u.repo.GetMembers(ctx, roomID)
+	if err != nil {
+		u.logger.Error("Failed to get chat room members", zap.Int("roomID", roomID), zap.Error(err))
+		return nil, err
+	}
+	return members, nil
+}
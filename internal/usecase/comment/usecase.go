@@ -2,47 +2,172 @@ package comment
 
 import (
 	"context"
+	"errors"
 
 	models "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/comment"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/event"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/post"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/trash"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/pagination"
 	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/repository/comment"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/text"
 	"go.uber.org/zap"
 )
 
+// Notifier tells interested users, including the ones mentioned as
+// @username, about new comments.
+type Notifier interface {
+	CommentCreated(ctx context.Context, c models.Comment, mentions []string)
+}
+
+// PostRepository tells whether a post takes new comments.
+type PostRepository interface {
+	GetByID(ctx context.Context, postID int) (post.Post, error)
+}
+
 type Usecase struct {
-	repo   *comment.Repository
-	logger *zap.Logger
+	repo      *comment.Repository
+	posts     PostRepository
+	publisher event.Publisher
+	notifier  Notifier
+	limits    pagination.Limits
+	maxDepth  int
+	logger    *zap.Logger
 }
 
-func New(repo *comment.Repository, logger *zap.Logger) *Usecase {
-	return &Usecase{repo: repo, logger: logger}
+// New creates the comment usecase. maxDepth limits how deeply replies may nest;
+// top-level comments have depth 0.
+func New(repo *comment.Repository, posts PostRepository, publisher event.Publisher, notifier Notifier, limits pagination.Limits, maxDepth int, logger *zap.Logger) *Usecase {
+	return &Usecase{repo: repo, posts: posts, publisher: publisher, notifier: notifier, limits: limits, maxDepth: maxDepth, logger: logger}
 }
 
-func (u *Usecase) GetCommentsByPost(ctx context.Context, postID int) ([]models.Comment, error) {
-	comments, err := u.repo.GetByPostID(ctx, postID)
+func (u *Usecase) GetCommentsByPost(ctx context.Context, postID int, page pagination.Page) ([]models.Comment, string, error) {
+	comments, next, err := u.repo.GetByPostID(ctx, postID, u.limits.Apply(page))
 	if err != nil {
 		u.logger.Error("Failed to fetch comments", zap.Int("postID", postID), zap.Error(err))
-		return nil, err
+		return nil, "", err
 	}
 	u.logger.Info("Comments fetched", zap.Int("postID", postID), zap.Int("count", len(comments)))
-	return comments, nil
+	return comments, next, nil
+}
+
+// GetThread returns a page of a post's top-level comments with their
+// replies nested into trees.
+func (u *Usecase) GetThread(ctx context.Context, postID int, page pagination.Page) ([]*models.Comment, string, error) {
+	comments, next, err := u.GetThreadFlat(ctx, postID, page)
+	if err != nil {
+		return nil, "", err
+	}
+	return models.BuildTree(comments), next, nil
 }
 
-func (u *Usecase) CreateComment(ctx context.Context, postID int, username, content string) error {
-	err := u.repo.Create(ctx, postID, username, content)
+// GetThreadFlat returns a page of a post's top-level comments, each followed
+// by its replies in thread path order.
+func (u *Usecase) GetThreadFlat(ctx context.Context, postID int, page pagination.Page) ([]models.Comment, string, error) {
+	comments, next, err := u.repo.GetThreadByPostID(ctx, postID, u.limits.Apply(page))
+	if err != nil {
+		u.logger.Error("Failed to fetch comment thread", zap.Int("postID", postID), zap.Error(err))
+		return nil, "", err
+	}
+	u.logger.Info("Comment thread fetched", zap.Int("postID", postID), zap.Int("count", len(comments)))
+	return comments, next, nil
+}
+
+// CreateComment stores a comment or a reply. Locked and archived posts take no comments.
+func (u *Usecase) CreateComment(ctx context.Context, postID int, parentID *int, username, content string) error {
+	p, err := u.posts.GetByID(ctx, postID)
+	switch {
+	case errors.Is(err, post.ErrNotFound):
+		return models.ErrPostNotFound
+	case err != nil:
+		u.logger.Error("Failed to get post", zap.Int("postID", postID), zap.Error(err))
+		return err
+	case p.Archived:
+		return models.ErrPostArchived
+	case p.Locked:
+		return models.ErrPostLocked
+	}
+
+	if parentID != nil {
+		parent, err := u.repo.GetByID(ctx, *parentID)
+		if errors.Is(err, models.ErrNotFound) || (err == nil && (parent.PostID != postID || parent.Deleted)) {
+			return models.ErrParentNotFound
+		}
+		if err != nil {
+			u.logger.Error("Failed to get parent comment", zap.Int("parentID", *parentID), zap.Error(err))
+			return err
+		}
+		if parent.Depth+1 > u.maxDepth {
+			return models.ErrMaxDepth
+		}
+	}
+
+	c, err := u.repo.Create(ctx, postID, parentID, username, content)
 	if err != nil {
 		u.logger.Error("Failed to create comment", zap.Int("postID", postID), zap.String("username", username), zap.Error(err))
 		return err
 	}
 	u.logger.Info("Comment created", zap.Int("postID", postID), zap.String("username", username))
+
+	u.notifier.CommentCreated(ctx, c, text.Mentions(c.Content))
+
+	if err := event.Publish(ctx, u.publisher, event.CommentCreated, c.ID, c.Preview(event.ExcerptLength)); err != nil {
+		u.logger.Error("Failed to publish comment event", zap.Int("commentID", c.ID), zap.Error(err))
+	}
+	return nil
+}
+
+// CommentsSince returns comments created after afterID, oldest first, to
+// replay them to a resuming client. Replays are capped at one maximum-size
+// page; clients further behind get event.ErrTooFarBehind.
+func (u *Usecase) CommentsSince(ctx context.Context, afterID int) ([]models.Comment, error) {
+	limit := u.limits.Apply(pagination.Page{Limit: u.limits.Max}).Limit
+	comments, err := u.repo.GetAfterID(ctx, afterID, limit+1)
+	if err != nil {
+		u.logger.Error("Failed to get new comments", zap.Int("afterID", afterID), zap.Error(err))
+		return nil, err
+	}
+	if len(comments) > limit {
+		return nil, event.ErrTooFarBehind
+	}
+	for i := range comments {
+		comments[i] = comments[i].Preview(event.ExcerptLength)
+	}
+	return comments, nil
+}
+
+func (u *Usecase) LatestCommentID(ctx context.Context) (int, error) {
+	id, err := u.repo.LatestID(ctx)
+	if err != nil {
+		u.logger.Error("Failed to get latest comment id", zap.Error(err))
+	}
+	return id, err
+}
+
+// DeleteComment moves a comment to the trash. A comment with visible replies
+// stays in the thread as a placeholder.
+func (u *Usecase) DeleteComment(ctx context.Context, commentID int, deletedBy string) error {
+	err := u.repo.Delete(ctx, commentID, deletedBy)
+	if err != nil {
+		if !errors.Is(err, models.ErrNotFound) {
+			u.logger.Error("Failed to delete comment", zap.Int("commentID", commentID), zap.Error(err))
+		}
+		return err
+	}
+	u.logger.Info("Comment deleted", zap.Int("commentID", commentID), zap.String("by", deletedBy))
 	return nil
 }
 
-func (u *Usecase) DeleteComment(ctx context.Context, commentID int) error {
-	err := u.repo.Delete(ctx, commentID)
+// RestoreComment brings a comment back from the trash. Comments of a deleted
+// post are restored with the post.
+func (u *Usecase) RestoreComment(ctx context.Context, commentID int) error {
+	err := u.repo.Restore(ctx, commentID)
 	if err != nil {
-		u.logger.Error("Failed to delete comment", zap.Int("commentID", commentID), zap.Error(err))
+		if !errors.Is(err, trash.ErrNotFound) && !errors.Is(err, trash.ErrParentDeleted) {
+			u.logger.Error("Failed to restore comment", zap.Int("commentID", commentID), zap.Error(err))
+		}
 		return err
 	}
-	u.logger.Info("Comment deleted", zap.Int("commentID", commentID))
+	u.logger.Info("Comment restored", zap.Int("commentID", commentID))
 	return nil
 }
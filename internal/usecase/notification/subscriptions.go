@@ -0,0 +1,46 @@
+package notification
+
+import (
+	"context"
+	"errors"
+
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/notification"
+	"go.uber.org/zap"
+)
+
+// Subscribe makes a user follow a topic or watch a post; exactly one of
+// topicID and postID must be set.
+func (u *UseCase) Subscribe(ctx context.Context, userID int32, topicID, postID *int) error {
+	if (topicID == nil) == (postID == nil) {
+		return notification.ErrInvalidTarget
+	}
+	if err := u.repo.Subscribe(ctx, userID, topicID, postID); err != nil {
+		if !errors.Is(err, notification.ErrTargetNotFound) {
+			u.logger.Error("Failed to subscribe", zap.Int32("userID", userID), zap.Error(err))
+		}
+		return err
+	}
+	u.logger.Info("Subscribed", zap.Int32("userID", userID), zap.Intp("topicID", topicID), zap.Intp("postID", postID))
+	return nil
+}
+
+// Unsubscribe reports whether the user was subscribed.
+func (u *UseCase) Unsubscribe(ctx context.Context, userID int32, topicID, postID *int) (bool, error) {
+	if (topicID == nil) == (postID == nil) {
+		return false, notification.ErrInvalidTarget
+	}
+	n, err := u.repo.Unsubscribe(ctx, userID, topicID, postID)
+	if err != nil {
+		u.logger.Error("Failed to unsubscribe", zap.Int32("userID", userID), zap.Error(err))
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (u *UseCase) GetSubscriptions(ctx context.Context, userID int32) ([]notification.Subscription, error) {
+	subs, err := u.repo.GetSubscriptions(ctx, userID)
+	if err != nil {
+		u.logger.Error("Failed to get subscriptions", zap.Int32("userID", userID), zap.Error(err))
+	}
+	return subs, err
+}
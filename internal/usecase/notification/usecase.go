@@ -0,0 +1,278 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+
+	chat "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/chat"
+	models "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/comment"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/event"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/notification"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/post"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/pagination"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/text"
+	"go.uber.org/zap"
+)
+
+type Repository interface {
+	Add(ctx context.Context, n notification.Notification, userIDs []int32) ([]notification.Notification, error)
+	GetByUser(ctx context.Context, userID int32, unreadOnly bool, page pagination.Page) ([]notification.Notification, string, error)
+	CountUnread(ctx context.Context, userID int32) (int, error)
+	MarkRead(ctx context.Context, userID int32, ids []int) (int64, error)
+	UserIDs(ctx context.Context, usernames []string) (map[string]int32, error)
+	Subscribe(ctx context.Context, userID int32, topicID, postID *int) error
+	Unsubscribe(ctx context.Context, userID int32, topicID, postID *int) (int64, error)
+	GetSubscriptions(ctx context.Context, userID int32) ([]notification.Subscription, error)
+	AddMentions(ctx context.Context, m notification.Mention, users map[string]int32) error
+	GetMentions(ctx context.Context, userID int32, page pagination.Page) ([]notification.Mention, string, error)
+	TopicSubscribers(ctx context.Context, topicID int) ([]int32, error)
+	PostSubscribers(ctx context.Context, postID int) ([]int32, error)
+}
+
+type PostRepository interface {
+	GetByID(ctx context.Context, postID int) (post.Post, error)
+}
+
+type CommentRepository interface {
+	GetByID(ctx context.Context, commentID int) (models.Comment, error)
+}
+
+// maxMentions is how many users a single text can notify by mentioning them.
+const maxMentions = 20
+
+type UseCase struct {
+	repo          Repository
+	posts         PostRepository
+	comments      CommentRepository
+	publisher     event.Publisher
+	limits        pagination.Limits
+	autoSubscribe bool
+	logger        *zap.Logger
+}
+
+// New creates the notification usecase. With autoSubscribe set, authors
+// start watching the posts they create.
+func New(repo Repository, posts PostRepository, comments CommentRepository, publisher event.Publisher, limits pagination.Limits, autoSubscribe bool, logger *zap.Logger) *UseCase {
+	return &UseCase{repo: repo, posts: posts, comments: comments, publisher: publisher, limits: limits, autoSubscribe: autoSubscribe, logger: logger}
+}
+
+// PostCreated notifies the topic's subscribers and the users mentioned in
+// the post and, if configured, makes the author watch the post.
+func (u *UseCase) PostCreated(ctx context.Context, p post.Post, mentions []string) {
+	usernames := append([]string{p.Username}, mentions...)
+	ids, err := u.repo.UserIDs(ctx, usernames)
+	if err != nil {
+		u.logger.Error("Failed to resolve notification recipients", zap.Strings("usernames", usernames), zap.Error(err))
+		return
+	}
+	authorID, known := ids[p.Username]
+	if u.autoSubscribe && known {
+		if err := u.repo.Subscribe(ctx, authorID, nil, &p.ID); err != nil {
+			u.logger.Error("Failed to subscribe author to post", zap.Int("postID", p.ID), zap.Error(err))
+		}
+	}
+
+	subscribers, err := u.repo.TopicSubscribers(ctx, p.TopicID)
+	if err != nil {
+		u.logger.Error("Failed to get topic subscribers", zap.Int("topicID", p.TopicID), zap.Error(err))
+		return
+	}
+	kinds := make(map[int32]string)
+	for _, id := range subscribers {
+		kinds[id] = notification.KindPost
+	}
+
+	excerpt := text.Excerpt(p.Content, event.ExcerptLength)
+	mentioned := u.recordMentions(ctx, notification.Mention{Actor: p.Username, PostID: &p.ID, Excerpt: excerpt}, mentions, ids)
+	for _, id := range mentioned {
+		kinds[id] = notification.KindMention
+	}
+	if known {
+		delete(kinds, authorID)
+	}
+
+	u.notifyAll(ctx, notification.Notification{
+		Actor:   p.Username,
+		PostID:  &p.ID,
+		Title:   text.Excerpt(p.Title, event.ExcerptLength),
+		Excerpt: excerpt,
+	}, kinds)
+}
+
+// CommentCreated notifies everyone watching the post, the users mentioned in
+// the comment and, for a reply, the author of the parent comment. Post authors
+// hear about comments only while they watch their post. Nobody is notified
+// about their own comments.
+func (u *UseCase) CommentCreated(ctx context.Context, c models.Comment, mentions []string) {
+	p, err := u.posts.GetByID(ctx, c.PostID)
+	if err != nil {
+		u.logger.Error("Failed to get commented post", zap.Int("postID", c.PostID), zap.Error(err))
+		return
+	}
+
+	usernames := append([]string{c.Username}, mentions...)
+	var parent models.Comment
+	if c.ParentID != nil {
+		parent, err = u.comments.GetByID(ctx, *c.ParentID)
+		if err != nil {
+			u.logger.Error("Failed to get parent comment", zap.Int("commentID", *c.ParentID), zap.Error(err))
+		} else if !parent.Deleted {
+			usernames = append(usernames, parent.Username)
+		}
+	}
+	ids, err := u.repo.UserIDs(ctx, usernames)
+	if err != nil {
+		u.logger.Error("Failed to resolve notification recipients", zap.Strings("usernames", usernames), zap.Error(err))
+		return
+	}
+	watchers, err := u.repo.PostSubscribers(ctx, c.PostID)
+	if err != nil {
+		u.logger.Error("Failed to get post subscribers", zap.Int("postID", c.PostID), zap.Error(err))
+		return
+	}
+
+	kinds := make(map[int32]string)
+	for _, id := range watchers {
+		kinds[id] = notification.KindComment
+	}
+	if id, ok := ids[parent.Username]; ok && parent.ID != 0 {
+		kinds[id] = notification.KindReply
+	}
+
+	postID, commentID := c.PostID, c.ID
+	excerpt := text.Excerpt(c.Content, event.ExcerptLength)
+	m := notification.Mention{Actor: c.Username, PostID: &postID, CommentID: &commentID, Excerpt: excerpt}
+	for _, id := range u.recordMentions(ctx, m, mentions, ids) {
+		kinds[id] = notification.KindMention
+	}
+	if id, ok := ids[c.Username]; ok {
+		delete(kinds, id)
+	}
+
+	u.notifyAll(ctx, notification.Notification{
+		Actor:     c.Username,
+		PostID:    &postID,
+		CommentID: &commentID,
+		Title:     text.Excerpt(p.Title, event.ExcerptLength),
+		Excerpt:   excerpt,
+	}, kinds)
+}
+
+// ChatMentioned notifies the users mentioned in a chat message.
+func (u *UseCase) ChatMentioned(ctx context.Context, msg chat.ChatMessage, mentions []string) {
+	ids, err := u.repo.UserIDs(ctx, mentions)
+	if err != nil {
+		u.logger.Error("Failed to resolve mentioned users", zap.Strings("usernames", mentions), zap.Error(err))
+		return
+	}
+
+	roomID, messageID := msg.RoomID, msg.ID
+	excerpt := text.Excerpt(msg.Content, event.ExcerptLength)
+	m := notification.Mention{Actor: msg.Username, RoomID: &roomID, MessageID: &messageID, Excerpt: excerpt}
+	kinds := make(map[int32]string)
+	for _, id := range u.recordMentions(ctx, m, mentions, ids) {
+		kinds[id] = notification.KindMention
+	}
+
+	u.notifyAll(ctx, notification.Notification{
+		Actor:     msg.Username,
+		RoomID:    &roomID,
+		MessageID: &messageID,
+		Excerpt:   excerpt,
+	}, kinds)
+}
+
+// recordMentions stores m for the mentioned users known in ids, except its
+// author, and returns them as username -> user id. Only the first
+// maxMentions usernames are considered.
+func (u *UseCase) recordMentions(ctx context.Context, m notification.Mention, mentions []string, ids map[string]int32) map[string]int32 {
+	if len(mentions) > maxMentions {
+		mentions = mentions[:maxMentions]
+	}
+	users := make(map[string]int32)
+	for _, username := range mentions {
+		if id, ok := ids[username]; ok && username != m.Actor {
+			users[username] = id
+		}
+	}
+	if len(users) == 0 {
+		return nil
+	}
+	if err := u.repo.AddMentions(ctx, m, users); err != nil {
+		u.logger.Error("Failed to store mentions", zap.String("actor", m.Actor), zap.Error(err))
+	}
+	return users
+}
+
+// notifyAll sends n to every user in kinds, with the kind given for that user.
+func (u *UseCase) notifyAll(ctx context.Context, n notification.Notification, kinds map[int32]string) {
+	recipients := make(map[string][]int32)
+	for id, kind := range kinds {
+		recipients[kind] = append(recipients[kind], id)
+	}
+	for kind, userIDs := range recipients {
+		n.Kind = kind
+		u.notify(ctx, n, userIDs)
+	}
+}
+
+// notify stores n for every recipient and pushes it to their live connections.
+func (u *UseCase) notify(ctx context.Context, n notification.Notification, userIDs []int32) {
+	if len(userIDs) == 0 {
+		return
+	}
+	created, err := u.repo.Add(ctx, n, userIDs)
+	if err != nil {
+		u.logger.Error("Failed to store notifications", zap.String("kind", n.Kind), zap.Intp("postID", n.PostID), zap.Intp("messageID", n.MessageID), zap.Error(err))
+		return
+	}
+	u.logger.Info("Notifications created", zap.String("kind", n.Kind), zap.Intp("postID", n.PostID), zap.Intp("messageID", n.MessageID), zap.Int("count", len(created)))
+
+	for _, n := range created {
+		data, err := json.Marshal(n)
+		if err == nil {
+			err = u.publisher.Publish(ctx, event.Event{Type: event.Notification, ID: n.ID, UserIDs: []int32{n.UserID}, Payload: data})
+		}
+		if err != nil {
+			u.logger.Error("Failed to publish notification", zap.Int("notificationID", n.ID), zap.Error(err))
+		}
+	}
+}
+
+func (u *UseCase) GetByUser(ctx context.Context, userID int32, unreadOnly bool, page pagination.Page) ([]notification.Notification, string, error) {
+	list, next, err := u.repo.GetByUser(ctx, userID, unreadOnly, u.limits.Apply(page))
+	if err != nil {
+		u.logger.Error("Failed to get notifications", zap.Int32("userID", userID), zap.Error(err))
+		return nil, "", err
+	}
+	return list, next, nil
+}
+
+func (u *UseCase) CountUnread(ctx context.Context, userID int32) (int, error) {
+	n, err := u.repo.CountUnread(ctx, userID)
+	if err != nil {
+		u.logger.Error("Failed to count unread notifications", zap.Int32("userID", userID), zap.Error(err))
+	}
+	return n, err
+}
+
+// MarkRead marks the given notifications as read, or all of them when ids is empty.
+func (u *UseCase) MarkRead(ctx context.Context, userID int32, ids []int) (int64, error) {
+	n, err := u.repo.MarkRead(ctx, userID, ids)
+	if err != nil {
+		u.logger.Error("Failed to mark notifications read", zap.Int32("userID", userID), zap.Error(err))
+		return 0, err
+	}
+	u.logger.Info("Notifications marked read", zap.Int32("userID", userID), zap.Int64("count", n))
+	return n, nil
+}
+
+// GetMentions returns the mentions of a user, newest first.
+func (u *UseCase) GetMentions(ctx context.Context, userID int32, page pagination.Page) ([]notification.Mention, string, error) {
+	mentions, next, err := u.repo.GetMentions(ctx, userID, u.limits.Apply(page))
+	if err != nil {
+		u.logger.Error("Failed to get mentions", zap.Int32("userID", userID), zap.Error(err))
+		return nil, "", err
+	}
+	return mentions, next, nil
+}
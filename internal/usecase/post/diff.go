@@ -0,0 +1,80 @@
+package post
+
+import (
+	"strings"
+
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/post"
+)
+
+// maxDiffCells bounds the LCS table of diffLines, which holds one int32 per
+// pair of changed lines, to 4 MiB.
+const maxDiffCells = 1 << 20
+
+// diffLines computes a line-based diff of a and b using the longest common
+// subsequence. Lines shared at the start and the end are matched up front,
+// so only the changed middle counts towards maxDiffCells.
+func diffLines(a, b string) ([]post.DiffLine, error) {
+	x := strings.Split(a, "\n")
+	y := strings.Split(b, "\n")
+
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]post.DiffLine, 0, max(len(x), len(y)))
+	for _, l := range x[:prefix] {
+		lines = append(lines, post.DiffLine{Op: "=", Text: l})
+	}
+
+	mx, my := x[prefix:len(x)-suffix], y[prefix:len(y)-suffix]
+	if (len(mx)+1)*(len(my)+1) > maxDiffCells {
+		return nil, post.ErrDiffTooLarge
+	}
+
+	// lcs[i][j] is the LCS length of mx[i:] and my[j:].
+	lcs := make([][]int32, len(mx)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(my)+1)
+	}
+	for i := len(mx) - 1; i >= 0; i-- {
+		for j := len(my) - 1; j >= 0; j-- {
+			if mx[i] == my[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(mx) && j < len(my) {
+		switch {
+		case mx[i] == my[j]:
+			lines = append(lines, post.DiffLine{Op: "=", Text: mx[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, post.DiffLine{Op: "-", Text: mx[i]})
+			i++
+		default:
+			lines = append(lines, post.DiffLine{Op: "+", Text: my[j]})
+			j++
+		}
+	}
+	for ; i < len(mx); i++ {
+		lines = append(lines, post.DiffLine{Op: "-", Text: mx[i]})
+	}
+	for ; j < len(my); j++ {
+		lines = append(lines, post.DiffLine{Op: "+", Text: my[j]})
+	}
+
+	for _, l := range x[len(x)-suffix:] {
+		lines = append(lines, post.DiffLine{Op: "=", Text: l})
+	}
+	return lines, nil
+}
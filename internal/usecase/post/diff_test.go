@@ -0,0 +1,93 @@
+package post
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/post"
+)
+
+// numbered returns n lines "<prefix>0" to "<prefix>n-1" joined by newlines.
+func numbered(prefix string, n int) string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = prefix + strconv.Itoa(i)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []post.DiffLine
+	}{
+		{
+			name: "empty",
+			want: []post.DiffLine{{Op: "=", Text: ""}},
+		},
+		{
+			name: "identical",
+			a:    "one\ntwo",
+			b:    "one\ntwo",
+			want: []post.DiffLine{{Op: "=", Text: "one"}, {Op: "=", Text: "two"}},
+		},
+		{
+			name: "all changed",
+			a:    "one\ntwo",
+			b:    "three\nfour",
+			want: []post.DiffLine{
+				{Op: "-", Text: "one"}, {Op: "-", Text: "two"},
+				{Op: "+", Text: "three"}, {Op: "+", Text: "four"},
+			},
+		},
+		{
+			name: "added to empty",
+			b:    "one",
+			want: []post.DiffLine{{Op: "-", Text: ""}, {Op: "+", Text: "one"}},
+		},
+		{
+			name: "changed middle",
+			a:    "head\nold\nshared\ntail",
+			b:    "head\nshared\nnew\ntail",
+			want: []post.DiffLine{
+				{Op: "=", Text: "head"}, {Op: "-", Text: "old"}, {Op: "=", Text: "shared"},
+				{Op: "+", Text: "new"}, {Op: "=", Text: "tail"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := diffLines(tt.a, tt.b)
+			if err != nil {
+				t.Fatalf("diffLines: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffLinesTooLarge(t *testing.T) {
+	if _, err := diffLines(numbered("a", 1100), numbered("b", 1100)); !errors.Is(err, post.ErrDiffTooLarge) {
+		t.Fatalf("err = %v, want ErrDiffTooLarge", err)
+	}
+}
+
+func TestDiffLinesSharedLinesDoNotCount(t *testing.T) {
+	shared := numbered("s", 5000)
+	got, err := diffLines(shared+"\nold\n"+shared, shared+"\nnew\n"+shared)
+	if err != nil {
+		t.Fatalf("diffLines: %v", err)
+	}
+	if len(got) != 10002 {
+		t.Fatalf("got %d lines, want 10002", len(got))
+	}
+	if got[5000] != (post.DiffLine{Op: "-", Text: "old"}) || got[5001] != (post.DiffLine{Op: "+", Text: "new"}) {
+		t.Fatalf("changed lines = %v, %v", got[5000], got[5001])
+	}
+}
@@ -2,63 +2,340 @@ package post
 
 import (
 	"context"
+	"errors"
 
+	models "github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/comment"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/event"
 	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/post"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/topic"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/trash"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/pagination"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/text"
 	"go.uber.org/zap"
 )
 
 type Repository interface {
-	GetAll(ctx context.Context) ([]post.Post, error)
-	GetByTopic(ctx context.Context, topicID int) ([]post.Post, error)
-	Create(ctx context.Context, p post.Post) error
-	Delete(ctx context.Context, postID int) error
+	List(ctx context.Context, f post.Filter, page pagination.Page) ([]post.Post, string, error)
+	GetByID(ctx context.Context, postID int) (post.Post, error)
+	GetAfterID(ctx context.Context, afterID, limit int) ([]post.Post, error)
+	LatestID(ctx context.Context) (int, error)
+	Create(ctx context.Context, p post.Post) (post.Post, error)
+	Update(ctx context.Context, postID int, title, content, editor string) error
+	Delete(ctx context.Context, postID int, deletedBy string) error
+	Restore(ctx context.Context, postID int) error
+	SetAccepted(ctx context.Context, postID int, commentID *int) error
+	SetState(ctx context.Context, postID int, s post.State) (post.Post, error)
+	GetRevisions(ctx context.Context, postID int) ([]post.Revision, error)
+	GetRevision(ctx context.Context, postID, version int) (post.Revision, error)
+}
+
+type TopicRepository interface {
+	GetByID(ctx context.Context, id int) (topic.Topic, error)
+}
+
+type CommentRepository interface {
+	GetThreadByPostID(ctx context.Context, postID int, page pagination.Page) ([]models.Comment, string, error)
+	GetByID(ctx context.Context, commentID int) (models.Comment, error)
+}
+
+// Tagger validates the tags given for a post.
+type Tagger interface {
+	Resolve(ctx context.Context, names []string) ([]string, error)
+}
+
+// Notifier tells interested users, including the ones mentioned as
+// @username, about new posts.
+type Notifier interface {
+	PostCreated(ctx context.Context, p post.Post, mentions []string)
 }
 
 type UseCase struct {
-	repo   Repository
-	logger *zap.Logger
+	repo      Repository
+	topics    TopicRepository
+	comments  CommentRepository
+	tags      Tagger
+	publisher event.Publisher
+	notifier  Notifier
+	limits    pagination.Limits
+	logger    *zap.Logger
 }
 
-func New(repo Repository, logger *zap.Logger) *UseCase {
-	return &UseCase{repo: repo, logger: logger}
+func New(repo Repository, topics TopicRepository, comments CommentRepository, tags Tagger, publisher event.Publisher, notifier Notifier, limits pagination.Limits, logger *zap.Logger) *UseCase {
+	return &UseCase{repo: repo, topics: topics, comments: comments, tags: tags, publisher: publisher, notifier: notifier, limits: limits, logger: logger}
 }
 
-func (uc *UseCase) GetAll(ctx context.Context) ([]post.Post, error) {
-	posts, err := uc.repo.GetAll(ctx)
+// List returns one page of the posts matching f, newest first.
+func (uc *UseCase) List(ctx context.Context, f post.Filter, page pagination.Page) ([]post.Post, string, error) {
+	posts, next, err := uc.repo.List(ctx, f, uc.limits.Apply(page))
 	if err != nil {
-		uc.logger.Error("Failed to get all posts", zap.Error(err))
-		return nil, err
+		uc.logger.Error("Failed to get posts", zap.Intp("topicID", f.TopicID), zap.String("tag", f.Tag), zap.Error(err))
+		return nil, "", err
 	}
-	uc.logger.Info("All posts fetched", zap.Int("count", len(posts)))
-	return posts, nil
+	uc.logger.Info("Posts fetched", zap.Intp("topicID", f.TopicID), zap.String("tag", f.Tag), zap.Int("count", len(posts)))
+	return posts, next, nil
+}
+
+// GetByID returns a post with its topic and the first page of its comment
+// tree. The accepted answer comes first: a top-level one with its replies, a
+// reply on its own while also staying in its thread.
+func (uc *UseCase) GetByID(ctx context.Context, postID int) (post.Details, error) {
+	p, err := uc.repo.GetByID(ctx, postID)
+	if err != nil {
+		uc.logger.Error("Failed to get post", zap.Int("postID", postID), zap.Error(err))
+		return post.Details{}, err
+	}
+
+	t, err := uc.topics.GetByID(ctx, p.TopicID)
+	if err != nil {
+		uc.logger.Error("Failed to get post topic", zap.Int("postID", postID), zap.Int("topicID", p.TopicID), zap.Error(err))
+		return post.Details{}, err
+	}
+
+	comments, next, err := uc.comments.GetThreadByPostID(ctx, postID, uc.limits.Apply(pagination.Page{}))
+	if err != nil {
+		uc.logger.Error("Failed to get post comments", zap.Int("postID", postID), zap.Error(err))
+		return post.Details{}, err
+	}
+
+	tree := models.BuildTree(comments)
+	if p.AcceptedCommentID != nil {
+		tree, err = uc.pinAccepted(ctx, tree, *p.AcceptedCommentID)
+		if err != nil {
+			uc.logger.Error("Failed to get accepted answer", zap.Int("postID", postID), zap.Intp("commentID", p.AcceptedCommentID), zap.Error(err))
+			return post.Details{}, err
+		}
+	}
+
+	uc.logger.Info("Post fetched", zap.Int("postID", postID), zap.Int("comments", len(comments)))
+	return post.Details{Post: p, Topic: t, Comments: tree, CommentsNextCursor: next}, nil
 }
 
-func (uc *UseCase) GetByTopic(ctx context.Context, topicID int) ([]post.Post, error) {
-	posts, err := uc.repo.GetByTopic(ctx, topicID)
+// pinAccepted moves the accepted answer to the top of tree. An answer that is
+// not one of its roots, being a reply or on a later page, is fetched and put
+// first without its replies.
+func (uc *UseCase) pinAccepted(ctx context.Context, tree []*models.Comment, acceptedID int) ([]*models.Comment, error) {
+	for i, c := range tree {
+		if c.ID == acceptedID {
+			copy(tree[1:i+1], tree[:i])
+			tree[0] = c
+			return tree, nil
+		}
+	}
+	accepted, err := uc.comments.GetByID(ctx, acceptedID)
+	if errors.Is(err, models.ErrNotFound) {
+		return tree, nil
+	}
 	if err != nil {
-		uc.logger.Error("Failed to get posts by topic", zap.Int("topicID", topicID), zap.Error(err))
 		return nil, err
 	}
-	uc.logger.Info("Posts fetched by topic", zap.Int("topicID", topicID), zap.Int("count", len(posts)))
-	return posts, nil
+	return append([]*models.Comment{&accepted}, tree...), nil
 }
 
+// Create stores a post, announces it to live clients and notifies interested
+// users. Failing to announce it is logged but does not fail the request.
 func (uc *UseCase) Create(ctx context.Context, p post.Post) error {
-	err := uc.repo.Create(ctx, p)
+	if _, err := uc.topics.GetByID(ctx, p.TopicID); err != nil {
+		return err
+	}
+	tags, err := uc.tags.Resolve(ctx, p.Tags)
+	if err != nil {
+		return err
+	}
+	p.Tags = tags
+
+	created, err := uc.repo.Create(ctx, p)
 	if err != nil {
 		uc.logger.Error("Failed to create post", zap.String("title", p.Title), zap.String("username", p.Username), zap.Error(err))
 		return err
 	}
 	uc.logger.Info("Post created", zap.String("title", p.Title), zap.String("username", p.Username))
+
+	if err := event.Publish(ctx, uc.publisher, event.PostCreated, created.ID, created.Preview(event.ExcerptLength)); err != nil {
+		uc.logger.Error("Failed to publish post event", zap.Int("postID", created.ID), zap.Error(err))
+	}
+	uc.notifier.PostCreated(ctx, created, text.Mentions(created.Title+"\n"+created.Content))
+	return nil
+}
+
+// PostsSince returns posts created after afterID, oldest first, to replay
+// them to a resuming client. Replays are capped at one maximum-size page;
+// clients further behind get event.ErrTooFarBehind.
+func (uc *UseCase) PostsSince(ctx context.Context, afterID int) ([]post.Post, error) {
+	limit := uc.limits.Apply(pagination.Page{Limit: uc.limits.Max}).Limit
+	posts, err := uc.repo.GetAfterID(ctx, afterID, limit+1)
+	if err != nil {
+		uc.logger.Error("Failed to get new posts", zap.Int("afterID", afterID), zap.Error(err))
+		return nil, err
+	}
+	if len(posts) > limit {
+		return nil, event.ErrTooFarBehind
+	}
+	for i := range posts {
+		posts[i] = posts[i].Preview(event.ExcerptLength)
+	}
+	return posts, nil
+}
+
+func (uc *UseCase) LatestPostID(ctx context.Context) (int, error) {
+	id, err := uc.repo.LatestID(ctx)
+	if err != nil {
+		uc.logger.Error("Failed to get latest post id", zap.Error(err))
+	}
+	return id, err
+}
+
+// Update edits a post on behalf of username. Only the author or an ADMIN may edit.
+func (uc *UseCase) Update(ctx context.Context, postID int, username, role, title, content string) error {
+	p, err := uc.repo.GetByID(ctx, postID)
+	if err != nil {
+		uc.logger.Error("Failed to get post for update", zap.Int("postID", postID), zap.Error(err))
+		return err
+	}
+	if p.Username != username && role != "ADMIN" {
+		uc.logger.Warn("Post update forbidden", zap.Int("postID", postID), zap.String("username", username))
+		return post.ErrForbidden
+	}
+	if p.Archived {
+		return post.ErrArchived
+	}
+
+	if err := uc.repo.Update(ctx, postID, title, content, username); err != nil {
+		uc.logger.Error("Failed to update post", zap.Int("postID", postID), zap.String("username", username), zap.Error(err))
+		return err
+	}
+	uc.logger.Info("Post updated", zap.Int("postID", postID), zap.String("username", username))
+	return nil
+}
+
+// Accept marks a comment as the answer to a post in a Q&A topic, replacing
+// any earlier one; a nil commentID withdraws it. Only the author of the post
+// or an ADMIN may do so.
+func (uc *UseCase) Accept(ctx context.Context, postID int, commentID *int, username, role string) error {
+	p, err := uc.repo.GetByID(ctx, postID)
+	if err != nil {
+		return err
+	}
+	if p.Username != username && role != "ADMIN" {
+		uc.logger.Warn("Answer accept forbidden", zap.Int("postID", postID), zap.String("username", username))
+		return post.ErrForbidden
+	}
+	if p.Archived {
+		return post.ErrArchived
+	}
+
+	t, err := uc.topics.GetByID(ctx, p.TopicID)
+	if err != nil {
+		uc.logger.Error("Failed to get post topic", zap.Int("postID", postID), zap.Int("topicID", p.TopicID), zap.Error(err))
+		return err
+	}
+	if !t.QA {
+		return post.ErrNotQA
+	}
+
+	if commentID != nil {
+		c, err := uc.comments.GetByID(ctx, *commentID)
+		if errors.Is(err, models.ErrNotFound) || (err == nil && (c.PostID != postID || c.Deleted)) {
+			return post.ErrAnswerNotFound
+		}
+		if err != nil {
+			uc.logger.Error("Failed to get comment", zap.Int("commentID", *commentID), zap.Error(err))
+			return err
+		}
+	}
+
+	if err := uc.repo.SetAccepted(ctx, postID, commentID); err != nil {
+		uc.logger.Error("Failed to set accepted answer", zap.Int("postID", postID), zap.Error(err))
+		return err
+	}
+	uc.logger.Info("Accepted answer set", zap.Int("postID", postID), zap.Intp("commentID", commentID), zap.String("username", username))
+	return nil
+}
+
+// SetState pins, locks or archives a post, or undoes that. Only ADMINs may do so.
+func (uc *UseCase) SetState(ctx context.Context, postID int, s post.State, username, role string) (post.Post, error) {
+	if role != "ADMIN" {
+		uc.logger.Warn("Post state change forbidden", zap.Int("postID", postID), zap.String("username", username))
+		return post.Post{}, post.ErrForbidden
+	}
+
+	p, err := uc.repo.SetState(ctx, postID, s)
+	if err != nil {
+		if !errors.Is(err, post.ErrNotFound) {
+			uc.logger.Error("Failed to set post state", zap.Int("postID", postID), zap.Error(err))
+		}
+		return post.Post{}, err
+	}
+	uc.logger.Info("Post state set", zap.Int("postID", postID), zap.Bool("pinned", p.Pinned),
+		zap.Bool("locked", p.Locked), zap.Bool("archived", p.Archived), zap.String("username", username))
+	return p, nil
+}
+
+func (uc *UseCase) GetRevisions(ctx context.Context, postID int) ([]post.Revision, error) {
+	revisions, err := uc.repo.GetRevisions(ctx, postID)
+	if err != nil {
+		uc.logger.Error("Failed to get post revisions", zap.Int("postID", postID), zap.Error(err))
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, post.ErrNotFound
+	}
+	uc.logger.Info("Post revisions fetched", zap.Int("postID", postID), zap.Int("count", len(revisions)))
+	return revisions, nil
+}
+
+// DiffRevisions returns a line-based diff between two versions of a post.
+func (uc *UseCase) DiffRevisions(ctx context.Context, postID, from, to int) (post.RevisionDiff, error) {
+	a, err := uc.repo.GetRevision(ctx, postID, from)
+	if err != nil {
+		uc.logger.Error("Failed to get post revision", zap.Int("postID", postID), zap.Int("version", from), zap.Error(err))
+		return post.RevisionDiff{}, err
+	}
+	b, err := uc.repo.GetRevision(ctx, postID, to)
+	if err != nil {
+		uc.logger.Error("Failed to get post revision", zap.Int("postID", postID), zap.Int("version", to), zap.Error(err))
+		return post.RevisionDiff{}, err
+	}
+
+	title, err := diffLines(a.Title, b.Title)
+	if err != nil {
+		return post.RevisionDiff{}, err
+	}
+	content, err := diffLines(a.Content, b.Content)
+	if err != nil {
+		return post.RevisionDiff{}, err
+	}
+	return post.RevisionDiff{
+		PostID:  postID,
+		From:    from,
+		To:      to,
+		Title:   title,
+		Content: content,
+	}, nil
+}
+
+// Delete moves a post to the trash together with its comments.
+func (uc *UseCase) Delete(ctx context.Context, postID int, deletedBy string) error {
+	err := uc.repo.Delete(ctx, postID, deletedBy)
+	if err != nil {
+		if !errors.Is(err, post.ErrNotFound) {
+			uc.logger.Error("Failed to delete post", zap.Int("postID", postID), zap.Error(err))
+		}
+		return err
+	}
+	uc.logger.Info("Post deleted", zap.Int("postID", postID), zap.String("by", deletedBy))
 	return nil
 }
 
-func (uc *UseCase) Delete(ctx context.Context, postID int) error {
-	err := uc.repo.Delete(ctx, postID)
+// Restore brings a post back from the trash with the comments that were
+// deleted along with it. Posts of a deleted topic are restored with the topic.
+func (uc *UseCase) Restore(ctx context.Context, postID int) error {
+	err := uc.repo.Restore(ctx, postID)
 	if err != nil {
-		uc.logger.Error("Failed to delete post", zap.Int("postID", postID), zap.Error(err))
+		if !errors.Is(err, trash.ErrNotFound) && !errors.Is(err, trash.ErrParentDeleted) {
+			uc.logger.Error("Failed to restore post", zap.Int("postID", postID), zap.Error(err))
+		}
 		return err
 	}
-	uc.logger.Info("Post deleted", zap.Int("postID", postID))
+	uc.logger.Info("Post restored", zap.Int("postID", postID))
 	return nil
 }
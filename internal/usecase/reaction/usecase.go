@@ -0,0 +1,121 @@
+package reaction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/event"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/reaction"
+	"go.uber.org/zap"
+)
+
+type Repository interface {
+	Toggle(ctx context.Context, userID int32, t reaction.Target, emoji string) (bool, error)
+	Counts(ctx context.Context, t reaction.Target) (reaction.Counts, error)
+	List(ctx context.Context, t reaction.Target) ([]reaction.Reaction, error)
+	MessageRoom(ctx context.Context, messageID int) (int, error)
+}
+
+// RoomGuard tells whether a user is banned from a chat room.
+type RoomGuard interface {
+	IsBanned(ctx context.Context, roomID int, userID int32) (bool, error)
+}
+
+type UseCase struct {
+	repo      Repository
+	rooms     RoomGuard
+	publisher event.Publisher
+	logger    *zap.Logger
+}
+
+func New(repo Repository, rooms RoomGuard, publisher event.Publisher, logger *zap.Logger) *UseCase {
+	return &UseCase{repo: repo, rooms: rooms, publisher: publisher, logger: logger}
+}
+
+// Toggle adds the user's reaction to a post, comment or chat message, or
+// removes it if the user already reacted with that emoji. Users banned from
+// a room cannot react to its messages, and nobody can react to an archived
+// post or its comments. Changes to chat message reactions are
+// pushed to the room's live clients.
+func (u *UseCase) Toggle(ctx context.Context, t reaction.Target, userID int32, username, emoji string) (reaction.Toggled, error) {
+	if !t.Valid() {
+		return reaction.Toggled{}, reaction.ErrInvalidTarget
+	}
+	if !reaction.Valid(emoji) {
+		return reaction.Toggled{}, reaction.ErrInvalidEmoji
+	}
+
+	res := reaction.Toggled{Target: t, Username: username, Emoji: emoji}
+	if t.MessageID != nil {
+		roomID, err := u.messageRoom(ctx, *t.MessageID, userID)
+		if err != nil {
+			return reaction.Toggled{}, err
+		}
+		res.RoomID = roomID
+	}
+
+	reacted, err := u.repo.Toggle(ctx, userID, t, emoji)
+	if err != nil {
+		if !errors.Is(err, reaction.ErrTargetNotFound) && !errors.Is(err, reaction.ErrArchived) {
+			u.logger.Error("Failed to toggle reaction", zap.Int32("userID", userID), zap.String("emoji", emoji), zap.Error(err))
+		}
+		return reaction.Toggled{}, err
+	}
+	res.Reacted = reacted
+
+	res.Reactions, err = u.repo.Counts(ctx, t)
+	if err != nil {
+		u.logger.Error("Failed to count reactions", zap.Error(err))
+		return reaction.Toggled{}, err
+	}
+
+	if t.MessageID != nil {
+		u.publish(ctx, res)
+	}
+	u.logger.Info("Reaction toggled", zap.String("username", username), zap.String("emoji", emoji), zap.Bool("reacted", reacted))
+	return res, nil
+}
+
+// messageRoom returns the room of a chat message the user may react to.
+func (u *UseCase) messageRoom(ctx context.Context, messageID int, userID int32) (int, error) {
+	roomID, err := u.repo.MessageRoom(ctx, messageID)
+	if err != nil {
+		if !errors.Is(err, reaction.ErrTargetNotFound) {
+			u.logger.Error("Failed to find chat message", zap.Int("messageID", messageID), zap.Error(err))
+		}
+		return 0, err
+	}
+	banned, err := u.rooms.IsBanned(ctx, roomID, userID)
+	if err != nil {
+		return 0, err
+	}
+	if banned {
+		return 0, reaction.ErrForbidden
+	}
+	return roomID, nil
+}
+
+// publish announces a changed chat message reaction. Failures are logged
+// only: the reaction itself is stored.
+func (u *UseCase) publish(ctx context.Context, res reaction.Toggled) {
+	data, err := json.Marshal(res)
+	if err == nil {
+		err = u.publisher.Publish(ctx, event.Event{Type: event.ChatMessageReaction, ID: *res.MessageID, RoomID: res.RoomID, Payload: data})
+	}
+	if err != nil {
+		u.logger.Error("Failed to publish reaction", zap.Int("messageID", *res.MessageID), zap.Error(err))
+	}
+}
+
+// List returns who reacted to a target, grouped by emoji.
+func (u *UseCase) List(ctx context.Context, t reaction.Target) ([]reaction.Reaction, error) {
+	if !t.Valid() {
+		return nil, reaction.ErrInvalidTarget
+	}
+	reactions, err := u.repo.List(ctx, t)
+	if err != nil {
+		u.logger.Error("Failed to list reactions", zap.Error(err))
+	}
+	return reactions, err
+}
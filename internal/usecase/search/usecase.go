@@ -0,0 +1,62 @@
+package search
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/search"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/pagination"
+	"go.uber.org/zap"
+)
+
+type Repository interface {
+	Search(ctx context.Context, q search.Query, limit, offset int) ([]search.Result, error)
+}
+
+// maxQueryLength bounds the search text; longer queries are cut.
+const maxQueryLength = 200
+
+type UseCase struct {
+	repo   Repository
+	limits pagination.Limits
+	logger *zap.Logger
+}
+
+func New(repo Repository, limits pagination.Limits, logger *zap.Logger) *UseCase {
+	return &UseCase{repo: repo, limits: limits, logger: logger}
+}
+
+// Search returns one page of ranked posts and comments matching q, and the
+// cursor of the next page, or "" on the last one.
+func (u *UseCase) Search(ctx context.Context, q search.Query, cursor string, limit int) ([]search.Result, string, error) {
+	q.Text = strings.TrimSpace(q.Text)
+	if q.Text == "" {
+		return nil, "", search.ErrEmptyQuery
+	}
+	if utf8.RuneCountInString(q.Text) > maxQueryLength {
+		q.Text = string([]rune(q.Text)[:maxQueryLength])
+	}
+	if q.Type != "" && q.Type != search.TypePost && q.Type != search.TypeComment {
+		return nil, "", search.ErrInvalidType
+	}
+	offset, err := pagination.DecodeOffset(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	page := u.limits.Apply(pagination.Page{Limit: limit})
+
+	results, err := u.repo.Search(ctx, q, page.Limit+1, offset)
+	if err != nil {
+		u.logger.Error("Search failed", zap.String("query", q.Text), zap.Error(err))
+		return nil, "", err
+	}
+
+	var next string
+	if len(results) > page.Limit {
+		results = results[:page.Limit]
+		next = pagination.EncodeOffset(offset + page.Limit)
+	}
+	u.logger.Info("Search done", zap.String("query", q.Text), zap.Int("count", len(results)))
+	return results, next, nil
+}
@@ -0,0 +1,116 @@
+package tag
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/tag"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/pagination"
+	"go.uber.org/zap"
+)
+
+type Repository interface {
+	List(ctx context.Context, prefix string, limit int) ([]tag.Tag, error)
+	Existing(ctx context.Context, names []string) ([]string, error)
+	Create(ctx context.Context, name string) error
+	Delete(ctx context.Context, name string) error
+}
+
+// autocompleteLimit is how many suggestions Autocomplete returns at most.
+const autocompleteLimit = 10
+
+type UseCase struct {
+	repo      Repository
+	limits    pagination.Limits
+	allowlist bool
+	logger    *zap.Logger
+}
+
+// New creates the tag usecase. With allowlist set, posts may only use tags
+// that an ADMIN has created; otherwise unknown tags are created on first use.
+func New(repo Repository, limits pagination.Limits, allowlist bool, logger *zap.Logger) *UseCase {
+	return &UseCase{repo: repo, limits: limits, allowlist: allowlist, logger: logger}
+}
+
+// Resolve normalizes the tags given for a post, drops duplicates and checks
+// them against the allowlist.
+func (u *UseCase) Resolve(ctx context.Context, names []string) ([]string, error) {
+	var tags []string
+	for _, name := range names {
+		t, err := tag.Normalize(name)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(tags, t) {
+			tags = append(tags, t)
+		}
+	}
+	if len(tags) > tag.MaxPerPost {
+		return nil, tag.ErrTooMany
+	}
+	if !u.allowlist || len(tags) == 0 {
+		return tags, nil
+	}
+
+	existing, err := u.repo.Existing(ctx, tags)
+	if err != nil {
+		u.logger.Error("Failed to check tags", zap.Strings("tags", tags), zap.Error(err))
+		return nil, err
+	}
+	if len(existing) < len(tags) {
+		return nil, tag.ErrNotAllowed
+	}
+	return tags, nil
+}
+
+// List returns tags with their post counts, most used first.
+func (u *UseCase) List(ctx context.Context, limit int) ([]tag.Tag, error) {
+	tags, err := u.repo.List(ctx, "", u.limits.Apply(pagination.Page{Limit: limit}).Limit)
+	if err != nil {
+		u.logger.Error("Failed to list tags", zap.Error(err))
+	}
+	return tags, err
+}
+
+// Autocomplete suggests the most used tags starting with prefix.
+func (u *UseCase) Autocomplete(ctx context.Context, prefix string) ([]tag.Tag, error) {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	tags, err := u.repo.List(ctx, prefix, autocompleteLimit)
+	if err != nil {
+		u.logger.Error("Failed to autocomplete tags", zap.String("prefix", prefix), zap.Error(err))
+	}
+	return tags, err
+}
+
+func (u *UseCase) Create(ctx context.Context, name string) (string, error) {
+	name, err := tag.Normalize(name)
+	if err != nil {
+		return "", err
+	}
+	if err := u.repo.Create(ctx, name); err != nil {
+		if !errors.Is(err, tag.ErrExists) {
+			u.logger.Error("Failed to create tag", zap.String("tag", name), zap.Error(err))
+		}
+		return "", err
+	}
+	u.logger.Info("Tag created", zap.String("tag", name))
+	return name, nil
+}
+
+// Delete removes a tag, also from the posts carrying it.
+func (u *UseCase) Delete(ctx context.Context, name string) error {
+	name, err := tag.Normalize(name)
+	if err != nil {
+		return tag.ErrNotFound
+	}
+	if err := u.repo.Delete(ctx, name); err != nil {
+		if !errors.Is(err, tag.ErrNotFound) {
+			u.logger.Error("Failed to delete tag", zap.String("tag", name), zap.Error(err))
+		}
+		return err
+	}
+	u.logger.Info("Tag deleted", zap.String("tag", name))
+	return nil
+}
@@ -2,38 +2,44 @@ package topic
 
 import (
 	"context"
+	"errors"
 
 	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/topic"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/trash"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/pagination"
 	"go.uber.org/zap"
 )
 
 type Repository interface {
-	GetAll(ctx context.Context) ([]topic.Topic, error)
-	Create(ctx context.Context, title, description string) error
-	Delete(ctx context.Context, id int64) error
+	GetAll(ctx context.Context, page pagination.Page) ([]topic.Topic, string, error)
+	Create(ctx context.Context, title, description string, qa bool) error
+	SetQA(ctx context.Context, id int, qa bool) error
+	Delete(ctx context.Context, id int64, deletedBy string) error
+	Restore(ctx context.Context, id int64) error
 }
 
 type UseCase struct {
 	repo   Repository
+	limits pagination.Limits
 	logger *zap.Logger
 }
 
-func New(repo Repository, logger *zap.Logger) *UseCase {
-	return &UseCase{repo: repo, logger: logger}
+func New(repo Repository, limits pagination.Limits, logger *zap.Logger) *UseCase {
+	return &UseCase{repo: repo, limits: limits, logger: logger}
 }
 
-func (uc *UseCase) GetAll(ctx context.Context) ([]topic.Topic, error) {
-	topics, err := uc.repo.GetAll(ctx)
+func (uc *UseCase) GetAll(ctx context.Context, page pagination.Page) ([]topic.Topic, string, error) {
+	topics, next, err := uc.repo.GetAll(ctx, uc.limits.Apply(page))
 	if err != nil {
 		uc.logger.Error("Failed to fetch topics", zap.Error(err))
-		return nil, err
+		return nil, "", err
 	}
 	uc.logger.Info("Topics fetched", zap.Int("count", len(topics)))
-	return topics, nil
+	return topics, next, nil
 }
 
-func (uc *UseCase) Create(ctx context.Context, title, description string) error {
-	err := uc.repo.Create(ctx, title, description)
+func (uc *UseCase) Create(ctx context.Context, title, description string, qa bool) error {
+	err := uc.repo.Create(ctx, title, description, qa)
 	if err != nil {
 		uc.logger.Error("Failed to create topic", zap.String("title", title), zap.Error(err))
 		return err
@@ -42,12 +48,43 @@ func (uc *UseCase) Create(ctx context.Context, title, description string) error
 	return nil
 }
 
-func (uc *UseCase) Delete(ctx context.Context, id int64) error {
-	err := uc.repo.Delete(ctx, id)
+// SetQA turns Q&A mode of a topic on or off. Accepted answers are kept when
+// it is turned off.
+func (uc *UseCase) SetQA(ctx context.Context, id int, qa bool) error {
+	err := uc.repo.SetQA(ctx, id, qa)
 	if err != nil {
-		uc.logger.Error("Failed to delete topic", zap.Int64("topicID", id), zap.Error(err))
+		if !errors.Is(err, topic.ErrNotFound) {
+			uc.logger.Error("Failed to set topic Q&A mode", zap.Int("topicID", id), zap.Error(err))
+		}
 		return err
 	}
-	uc.logger.Info("Topic deleted", zap.Int64("topicID", id))
+	uc.logger.Info("Topic Q&A mode set", zap.Int("topicID", id), zap.Bool("qa", qa))
+	return nil
+}
+
+// Delete moves a topic to the trash together with its posts and comments.
+func (uc *UseCase) Delete(ctx context.Context, id int64, deletedBy string) error {
+	err := uc.repo.Delete(ctx, id, deletedBy)
+	if err != nil {
+		if !errors.Is(err, topic.ErrNotFound) {
+			uc.logger.Error("Failed to delete topic", zap.Int64("topicID", id), zap.Error(err))
+		}
+		return err
+	}
+	uc.logger.Info("Topic deleted", zap.Int64("topicID", id), zap.String("by", deletedBy))
+	return nil
+}
+
+// Restore brings a topic back from the trash with the posts and comments
+// that were deleted along with it.
+func (uc *UseCase) Restore(ctx context.Context, id int64) error {
+	err := uc.repo.Restore(ctx, id)
+	if err != nil {
+		if !errors.Is(err, trash.ErrNotFound) {
+			uc.logger.Error("Failed to restore topic", zap.Int64("topicID", id), zap.Error(err))
+		}
+		return err
+	}
+	uc.logger.Info("Topic restored", zap.Int64("topicID", id))
 	return nil
 }
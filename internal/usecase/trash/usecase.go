@@ -0,0 +1,53 @@
+package trash
+
+import (
+	"context"
+	"time"
+
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/domain/trash"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/pagination"
+	"github.com/LevTrot/sstu-golang-adminGoForum-backend/backend/internal/text"
+	"go.uber.org/zap"
+)
+
+type Repository interface {
+	List(ctx context.Context, typ string, page pagination.Page) ([]trash.Item, string, error)
+}
+
+// excerptLength bounds the content shown for each item in the trash.
+const excerptLength = 200
+
+type UseCase struct {
+	repo   Repository
+	limits pagination.Limits
+	grace  time.Duration
+	logger *zap.Logger
+}
+
+// New creates the trash usecase. grace is how long deleted items are kept
+// before the purge job removes them.
+func New(repo Repository, limits pagination.Limits, grace time.Duration, logger *zap.Logger) *UseCase {
+	return &UseCase{repo: repo, limits: limits, grace: grace, logger: logger}
+}
+
+// List returns one page of the trash, most recently deleted first. typ
+// limits it to topics, posts or comments; empty means all of them.
+func (u *UseCase) List(ctx context.Context, typ string, page pagination.Page) ([]trash.Item, string, error) {
+	switch typ {
+	case "", trash.TypeTopic, trash.TypePost, trash.TypeComment:
+	default:
+		return nil, "", trash.ErrInvalidType
+	}
+
+	items, next, err := u.repo.List(ctx, typ, u.limits.Apply(page))
+	if err != nil {
+		u.logger.Error("Failed to fetch trash", zap.String("type", typ), zap.Error(err))
+		return nil, "", err
+	}
+	for i := range items {
+		items[i].Excerpt = text.Excerpt(items[i].Excerpt, excerptLength)
+		items[i].PurgeAt = items[i].DeletedAt.Add(u.grace)
+	}
+	u.logger.Info("Trash fetched", zap.String("type", typ), zap.Int("count", len(items)))
+	return items, next, nil
+}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS backend_schema.post_revisions;
+ALTER TABLE backend_schema.posts DROP COLUMN IF EXISTS updated_at;
@@ -0,0 +1,17 @@
+ALTER TABLE backend_schema.posts ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS backend_schema.post_revisions (
+    id SERIAL PRIMARY KEY,
+    post_id INTEGER NOT NULL REFERENCES backend_schema.posts(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    content TEXT NOT NULL,
+    username TEXT NOT NULL,
+    timestamp TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (post_id, version)
+);
+
+INSERT INTO backend_schema.post_revisions (post_id, version, title, content, username, timestamp)
+SELECT id, 1, title, content, username, COALESCE(timestamp, NOW())
+FROM backend_schema.posts
+ON CONFLICT (post_id, version) DO NOTHING;
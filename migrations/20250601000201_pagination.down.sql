@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS backend_schema.comments_post_timestamp_id_idx;
+DROP INDEX IF EXISTS backend_schema.posts_topic_timestamp_id_idx;
+DROP INDEX IF EXISTS backend_schema.posts_timestamp_id_idx;
+DROP INDEX IF EXISTS backend_schema.topics_created_at_id_idx;
+ALTER TABLE backend_schema.topics DROP COLUMN IF EXISTS created_at;
@@ -0,0 +1,6 @@
+ALTER TABLE backend_schema.topics ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+CREATE INDEX IF NOT EXISTS topics_created_at_id_idx ON backend_schema.topics (created_at, id);
+CREATE INDEX IF NOT EXISTS posts_timestamp_id_idx ON backend_schema.posts (timestamp DESC, id DESC);
+CREATE INDEX IF NOT EXISTS posts_topic_timestamp_id_idx ON backend_schema.posts (topic_id, timestamp DESC, id DESC);
+CREATE INDEX IF NOT EXISTS comments_post_timestamp_id_idx ON backend_schema.comments (post_id, timestamp, id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS backend_schema.comments_parent_id_idx;
+
+ALTER TABLE backend_schema.comments
+    DROP COLUMN IF EXISTS is_deleted,
+    DROP COLUMN IF EXISTS depth,
+    DROP COLUMN IF EXISTS parent_id;
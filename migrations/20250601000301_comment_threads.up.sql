@@ -0,0 +1,6 @@
+ALTER TABLE backend_schema.comments
+    ADD COLUMN IF NOT EXISTS parent_id INT REFERENCES backend_schema.comments(id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS depth INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS is_deleted BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS comments_parent_id_idx ON backend_schema.comments (parent_id);
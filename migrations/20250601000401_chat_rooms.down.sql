@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS backend_schema.chat_room_members;
+DROP INDEX IF EXISTS backend_schema.chat_messages_room_timestamp_idx;
+ALTER TABLE backend_schema.chat_messages DROP COLUMN IF EXISTS room_id;
+DROP TABLE IF EXISTS backend_schema.chat_rooms;
@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS backend_schema.chat_rooms (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    title TEXT NOT NULL DEFAULT '',
+    topic_id INTEGER UNIQUE REFERENCES backend_schema.topics(id) ON DELETE CASCADE,
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO backend_schema.chat_rooms (name, title) VALUES ('general', 'Общий чат')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO backend_schema.chat_rooms (name, title, topic_id)
+SELECT 'topic-' || id, title, id FROM backend_schema.topics
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE backend_schema.chat_messages
+    ADD COLUMN IF NOT EXISTS room_id INTEGER REFERENCES backend_schema.chat_rooms(id) ON DELETE CASCADE;
+
+UPDATE backend_schema.chat_messages
+SET room_id = (SELECT id FROM backend_schema.chat_rooms WHERE name = 'general')
+WHERE room_id IS NULL;
+
+ALTER TABLE backend_schema.chat_messages ALTER COLUMN room_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS chat_messages_room_timestamp_idx ON backend_schema.chat_messages (room_id, timestamp);
+
+CREATE TABLE IF NOT EXISTS backend_schema.chat_room_members (
+    room_id INTEGER NOT NULL REFERENCES backend_schema.chat_rooms(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL,
+    username TEXT NOT NULL,
+    joined_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (room_id, user_id)
+);
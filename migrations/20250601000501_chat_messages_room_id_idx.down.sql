@@ -0,0 +1 @@
+DROP INDEX IF EXISTS backend_schema.chat_messages_room_id_id_idx;
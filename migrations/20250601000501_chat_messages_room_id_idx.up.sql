@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS chat_messages_room_id_id_idx ON backend_schema.chat_messages (room_id, id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS backend_schema.direct_messages;
+DROP TABLE IF EXISTS backend_schema.users;
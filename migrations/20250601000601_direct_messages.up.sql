@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS backend_schema.users (
+    id INTEGER PRIMARY KEY,
+    username TEXT NOT NULL UNIQUE,
+    role TEXT NOT NULL DEFAULT '',
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS backend_schema.direct_messages (
+    id SERIAL PRIMARY KEY,
+    sender_id INTEGER NOT NULL REFERENCES backend_schema.users(id) ON DELETE CASCADE,
+    recipient_id INTEGER NOT NULL REFERENCES backend_schema.users(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    read_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS direct_messages_pair_idx
+    ON backend_schema.direct_messages (LEAST(sender_id, recipient_id), GREATEST(sender_id, recipient_id), id);
+CREATE INDEX IF NOT EXISTS direct_messages_unread_idx
+    ON backend_schema.direct_messages (recipient_id) WHERE read_at IS NULL;
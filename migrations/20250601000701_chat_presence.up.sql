@@ -0,0 +1,13 @@
+-- Online users per room and server instance. The data is transient, so the
+-- table is unlogged; rows of instances that stop refreshing them are purged.
+CREATE UNLOGGED TABLE IF NOT EXISTS backend_schema.chat_presence (
+    instance_id TEXT NOT NULL,
+    room_id INTEGER NOT NULL REFERENCES backend_schema.chat_rooms(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL,
+    username TEXT NOT NULL,
+    connections INTEGER NOT NULL,
+    seen_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (instance_id, room_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS chat_presence_room_idx ON backend_schema.chat_presence (room_id, user_id);
@@ -0,0 +1,4 @@
+ALTER TABLE backend_schema.chat_messages
+    DROP COLUMN IF EXISTS deleted_by,
+    DROP COLUMN IF EXISTS deleted_at,
+    DROP COLUMN IF EXISTS edited_at;
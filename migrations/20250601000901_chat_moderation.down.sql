@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS backend_schema.chat_messages_room_username_idx;
+DROP TABLE IF EXISTS backend_schema.chat_bans;
+DROP TABLE IF EXISTS backend_schema.chat_mutes;
+ALTER TABLE backend_schema.chat_rooms DROP COLUMN IF EXISTS slow_mode_seconds;
@@ -0,0 +1,30 @@
+ALTER TABLE backend_schema.chat_rooms
+    ADD COLUMN IF NOT EXISTS slow_mode_seconds INTEGER NOT NULL DEFAULT 0;
+
+-- A mute without room_id applies to every room.
+CREATE TABLE IF NOT EXISTS backend_schema.chat_mutes (
+    id SERIAL PRIMARY KEY,
+    room_id INTEGER REFERENCES backend_schema.chat_rooms(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL,
+    username TEXT NOT NULL,
+    until TIMESTAMPTZ NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS chat_mutes_user_idx ON backend_schema.chat_mutes (user_id, until);
+
+CREATE TABLE IF NOT EXISTS backend_schema.chat_bans (
+    room_id INTEGER NOT NULL REFERENCES backend_schema.chat_rooms(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL,
+    username TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (room_id, user_id)
+);
+
+-- Slow mode looks up a user's latest message in a room.
+CREATE INDEX IF NOT EXISTS chat_messages_room_username_idx
+    ON backend_schema.chat_messages (room_id, username, timestamp);
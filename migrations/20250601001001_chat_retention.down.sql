@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS backend_schema.chat_messages_archive;
+ALTER TABLE backend_schema.chat_rooms
+    DROP COLUMN IF EXISTS retention_archive,
+    DROP COLUMN IF EXISTS retention_max_messages,
+    DROP COLUMN IF EXISTS retention_max_age_seconds;
@@ -0,0 +1,19 @@
+-- Per-room overrides of the global retention policy; NULL uses the global value.
+ALTER TABLE backend_schema.chat_rooms
+    ADD COLUMN IF NOT EXISTS retention_max_age_seconds INTEGER,
+    ADD COLUMN IF NOT EXISTS retention_max_messages INTEGER,
+    ADD COLUMN IF NOT EXISTS retention_archive BOOLEAN;
+
+CREATE TABLE IF NOT EXISTS backend_schema.chat_messages_archive (
+    id INTEGER PRIMARY KEY,
+    room_id INTEGER NOT NULL,
+    username TEXT NOT NULL,
+    content TEXT NOT NULL,
+    timestamp TIMESTAMPTZ NOT NULL,
+    edited_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    deleted_by TEXT,
+    archived_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS chat_messages_archive_room_idx ON backend_schema.chat_messages_archive (room_id, id);
@@ -0,0 +1,7 @@
+ALTER TABLE backend_schema.chat_messages_archive
+    DROP COLUMN IF EXISTS attachment,
+    DROP COLUMN IF EXISTS kind;
+
+ALTER TABLE backend_schema.chat_messages
+    DROP COLUMN IF EXISTS attachment,
+    DROP COLUMN IF EXISTS kind;
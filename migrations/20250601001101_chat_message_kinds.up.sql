@@ -0,0 +1,7 @@
+ALTER TABLE backend_schema.chat_messages
+    ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'text',
+    ADD COLUMN IF NOT EXISTS attachment JSONB;
+
+ALTER TABLE backend_schema.chat_messages_archive
+    ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'text',
+    ADD COLUMN IF NOT EXISTS attachment JSONB;
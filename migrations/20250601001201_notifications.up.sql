@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS backend_schema.notifications (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES backend_schema.users(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    post_id INTEGER NOT NULL REFERENCES backend_schema.posts(id) ON DELETE CASCADE,
+    comment_id INTEGER REFERENCES backend_schema.comments(id) ON DELETE CASCADE,
+    title TEXT NOT NULL DEFAULT '',
+    excerpt TEXT NOT NULL DEFAULT '',
+    read_at TIMESTAMPTZ,
+    timestamp TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS notifications_user_idx
+    ON backend_schema.notifications (user_id, timestamp DESC, id DESC);
+CREATE INDEX IF NOT EXISTS notifications_unread_idx
+    ON backend_schema.notifications (user_id) WHERE read_at IS NULL;
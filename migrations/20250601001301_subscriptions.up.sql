@@ -0,0 +1,16 @@
+-- A subscription is either to a topic or to a post.
+CREATE TABLE IF NOT EXISTS backend_schema.subscriptions (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES backend_schema.users(id) ON DELETE CASCADE,
+    topic_id INTEGER REFERENCES backend_schema.topics(id) ON DELETE CASCADE,
+    post_id INTEGER REFERENCES backend_schema.posts(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK ((topic_id IS NULL) <> (post_id IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS subscriptions_topic_idx
+    ON backend_schema.subscriptions (topic_id, user_id) WHERE topic_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS subscriptions_post_idx
+    ON backend_schema.subscriptions (post_id, user_id) WHERE post_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS subscriptions_user_idx
+    ON backend_schema.subscriptions (user_id, created_at DESC);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS backend_schema.mentions;
+
+DELETE FROM backend_schema.notifications WHERE post_id IS NULL;
+ALTER TABLE backend_schema.notifications
+    DROP COLUMN IF EXISTS message_id,
+    DROP COLUMN IF EXISTS room_id,
+    ALTER COLUMN post_id SET NOT NULL;
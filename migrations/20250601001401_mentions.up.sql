@@ -0,0 +1,23 @@
+-- Notifications about chat mentions point at a message instead of a post.
+ALTER TABLE backend_schema.notifications
+    ALTER COLUMN post_id DROP NOT NULL,
+    ADD COLUMN IF NOT EXISTS room_id INTEGER REFERENCES backend_schema.chat_rooms(id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS message_id INTEGER REFERENCES backend_schema.chat_messages(id) ON DELETE CASCADE;
+
+CREATE TABLE IF NOT EXISTS backend_schema.mentions (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES backend_schema.users(id) ON DELETE CASCADE,
+    username TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    post_id INTEGER REFERENCES backend_schema.posts(id) ON DELETE CASCADE,
+    comment_id INTEGER REFERENCES backend_schema.comments(id) ON DELETE CASCADE,
+    room_id INTEGER REFERENCES backend_schema.chat_rooms(id) ON DELETE CASCADE,
+    message_id INTEGER REFERENCES backend_schema.chat_messages(id) ON DELETE CASCADE,
+    excerpt TEXT NOT NULL DEFAULT '',
+    timestamp TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS mentions_user_idx
+    ON backend_schema.mentions (user_id, timestamp DESC, id DESC);
+CREATE INDEX IF NOT EXISTS mentions_post_idx
+    ON backend_schema.mentions (post_id) WHERE post_id IS NOT NULL;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS backend_schema.comments_search_idx;
+DROP INDEX IF EXISTS backend_schema.posts_search_idx;
+ALTER TABLE backend_schema.comments DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE backend_schema.posts DROP COLUMN IF EXISTS search_vector;
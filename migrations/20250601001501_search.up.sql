@@ -0,0 +1,16 @@
+-- Postgres' russian configuration stems Cyrillic words with the Russian
+-- stemmer and ASCII words with the English one, so a single vector serves
+-- both languages.
+ALTER TABLE backend_schema.posts
+    ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('russian', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('russian', coalesce(content, '')), 'B')
+    ) STORED;
+
+ALTER TABLE backend_schema.comments
+    ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        to_tsvector('russian', coalesce(content, ''))
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS posts_search_idx ON backend_schema.posts USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS comments_search_idx ON backend_schema.comments USING GIN (search_vector);
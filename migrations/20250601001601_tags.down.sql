@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS backend_schema.post_tags;
+DROP TABLE IF EXISTS backend_schema.tags;
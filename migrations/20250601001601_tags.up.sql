@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS backend_schema.tags (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS backend_schema.post_tags (
+    post_id INTEGER NOT NULL REFERENCES backend_schema.posts(id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES backend_schema.tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (post_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS post_tags_tag_idx ON backend_schema.post_tags (tag_id, post_id);
+-- Autocomplete looks tags up by prefix.
+CREATE INDEX IF NOT EXISTS tags_name_prefix_idx ON backend_schema.tags (name text_pattern_ops);
@@ -0,0 +1,18 @@
+-- A reaction is on exactly one post, comment or chat message.
+CREATE TABLE IF NOT EXISTS backend_schema.reactions (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES backend_schema.users(id) ON DELETE CASCADE,
+    post_id INTEGER REFERENCES backend_schema.posts(id) ON DELETE CASCADE,
+    comment_id INTEGER REFERENCES backend_schema.comments(id) ON DELETE CASCADE,
+    message_id INTEGER REFERENCES backend_schema.chat_messages(id) ON DELETE CASCADE,
+    emoji TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (num_nonnulls(post_id, comment_id, message_id) = 1)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS reactions_post_idx
+    ON backend_schema.reactions (post_id, user_id, emoji) WHERE post_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS reactions_comment_idx
+    ON backend_schema.reactions (comment_id, user_id, emoji) WHERE comment_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS reactions_message_idx
+    ON backend_schema.reactions (message_id, user_id, emoji) WHERE message_id IS NOT NULL;
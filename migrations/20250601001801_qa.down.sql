@@ -0,0 +1,2 @@
+ALTER TABLE backend_schema.posts DROP COLUMN IF EXISTS accepted_comment_id;
+ALTER TABLE backend_schema.topics DROP COLUMN IF EXISTS is_qa;
@@ -0,0 +1,6 @@
+-- In Q&A topics the author of a post, or an ADMIN, can accept one comment as its answer.
+ALTER TABLE backend_schema.topics ADD COLUMN IF NOT EXISTS is_qa BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE backend_schema.posts ADD COLUMN IF NOT EXISTS accepted_comment_id INTEGER
+    REFERENCES backend_schema.comments(id) ON DELETE SET NULL;
+
+UPDATE backend_schema.topics SET is_qa = TRUE WHERE title = 'Вопросы по Go';
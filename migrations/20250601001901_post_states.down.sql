@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS backend_schema.posts_topic_pinned_idx;
+ALTER TABLE backend_schema.posts
+    DROP COLUMN IF EXISTS pinned,
+    DROP COLUMN IF EXISTS locked,
+    DROP COLUMN IF EXISTS archived;
@@ -0,0 +1,9 @@
+-- Moderation states of posts: pinned posts are listed first in their topic,
+-- locked ones take no comments, archived ones are read-only and left out of lists.
+ALTER TABLE backend_schema.posts
+    ADD COLUMN IF NOT EXISTS pinned BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS locked BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS archived BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS posts_topic_pinned_idx
+    ON backend_schema.posts (topic_id, timestamp DESC) WHERE pinned;
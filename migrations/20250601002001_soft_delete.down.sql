@@ -0,0 +1,12 @@
+-- Rows still in the trash are removed; without the columns they would reappear.
+DELETE FROM backend_schema.topics WHERE deleted_at IS NOT NULL;
+DELETE FROM backend_schema.posts WHERE deleted_at IS NOT NULL;
+DELETE FROM backend_schema.comments WHERE deleted_at IS NOT NULL AND NOT is_deleted;
+
+DROP INDEX IF EXISTS backend_schema.comments_deleted_idx;
+DROP INDEX IF EXISTS backend_schema.posts_deleted_idx;
+DROP INDEX IF EXISTS backend_schema.topics_deleted_idx;
+
+ALTER TABLE backend_schema.comments DROP COLUMN IF EXISTS deleted_at, DROP COLUMN IF EXISTS deleted_by;
+ALTER TABLE backend_schema.posts DROP COLUMN IF EXISTS deleted_at, DROP COLUMN IF EXISTS deleted_by;
+ALTER TABLE backend_schema.topics DROP COLUMN IF EXISTS deleted_at, DROP COLUMN IF EXISTS deleted_by;
@@ -0,0 +1,17 @@
+-- Deleted topics, posts and comments stay in the trash until the purge job
+-- removes them. Rows deleted along with their topic or post share its
+-- deleted_at, which is how a restore finds them again.
+ALTER TABLE backend_schema.topics
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS deleted_by TEXT;
+ALTER TABLE backend_schema.posts
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS deleted_by TEXT;
+-- A deleted comment with visible replies keeps is_deleted set and is shown as a placeholder.
+ALTER TABLE backend_schema.comments
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS deleted_by TEXT;
+
+CREATE INDEX IF NOT EXISTS topics_deleted_idx ON backend_schema.topics (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS posts_deleted_idx ON backend_schema.posts (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS comments_deleted_idx ON backend_schema.comments (deleted_at) WHERE deleted_at IS NOT NULL;
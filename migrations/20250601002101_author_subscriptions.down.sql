@@ -0,0 +1,3 @@
+-- Backfilled subscriptions cannot be told apart from ones users made
+-- themselves, so they are kept.
+SELECT 1;
@@ -0,0 +1,7 @@
+-- Authors of posts written before subscriptions existed watch their posts, as
+-- authors of new posts do with AUTO_SUBSCRIBE_OWN_POSTS set.
+INSERT INTO backend_schema.subscriptions (user_id, post_id, created_at)
+SELECT u.id, p.id, COALESCE(p.timestamp, now())
+FROM backend_schema.posts p
+JOIN backend_schema.users u ON u.username = p.username
+ON CONFLICT DO NOTHING;